@@ -0,0 +1,256 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/ruleset"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// defaultBestHits is how many of the best hits a backtest reports by default.
+const defaultBestHits = 10
+
+// BacktestRequest asks how a fixed set of bets would have done in the stored
+// draws. The range of draws is inclusive and may be given by draw number, by
+// date, or both; without a range every stored draw is used.
+type BacktestRequest struct {
+	Bets           []NumbersInput `json:"bets"`
+	FromDrawNumber int            `json:"fromDrawNumber,omitempty"`
+	ToDrawNumber   int            `json:"toDrawNumber,omitempty"`
+	FromDate       string         `json:"fromDate,omitempty"`
+	ToDate         string         `json:"toDate,omitempty"`
+	BestHits       int            `json:"bestHits,omitempty"`
+	Strict         bool           `json:"strict"`
+}
+
+// BacktestResponse sums up a backtest. Prizes adds up the prizes of every
+// bet in every draw, so that it counts the winning shares of each group and
+// holds the fixed prize winnings. WinningsCents adds the group prizes of
+// draws with stored group prize amounts to the fixed prizes; draws without
+// them are counted in DrawsWithoutGroupPrizes. Bets that the rules of a draw
+// do not allow, such as bets with 46 to 49 in a 6/45 draw, are left out of
+// that draw and reported in Skipped.
+type BacktestResponse struct {
+	Draws                   int              `json:"draws"`
+	FromDrawNumber          int              `json:"fromDrawNumber,omitempty"`
+	ToDrawNumber            int              `json:"toDrawNumber,omitempty"`
+	Prizes                  prizetable.Prize `json:"prizes"`
+	WinningBets             int              `json:"winningBets"`
+	FixedWinningsCents      int              `json:"fixedWinningsCents"`
+	WinningsCents           int              `json:"winningsCents"`
+	DrawsWithoutGroupPrizes int              `json:"drawsWithoutGroupPrizes"`
+	CostCents               int              `json:"costCents"`
+	NetCents                int              `json:"netCents"`
+	BestHits                []BacktestHit    `json:"bestHits"`
+	Errors                  []BetError       `json:"errors,omitempty"`
+	Skipped                 []BacktestSkip   `json:"skipped,omitempty"`
+}
+
+// BacktestSkip reports a bet left out of the draws of a ruleset that does not
+// allow it.
+type BacktestSkip struct {
+	Index   int    `json:"index"`
+	Input   string `json:"input"`
+	Ruleset string `json:"ruleset"`
+	Reason  string `json:"reason"`
+	Draws   int    `json:"draws"`
+}
+
+// BacktestHit is the result of a bet in one draw.
+type BacktestHit struct {
+	DrawNumber int    `json:"drawNumber"`
+	DrawDate   string `json:"drawDate"`
+	totodraw.BetResult
+}
+
+func backtest(request BacktestRequest) (BacktestResponse, error) {
+	var response BacktestResponse
+
+	rules := ruleset.Default.Latest()
+
+	var bets []totodraw.Bet
+	var err error
+	if request.Strict {
+		bets, err = mapBetStringsToBets(request.Bets, rules)
+		if err != nil {
+			return response, err
+		}
+	} else {
+		bets, response.Errors = mapValidBetStringsToBets(request.Bets, rules)
+	}
+
+	draws, err := drawsInRange(request.FromDrawNumber, request.ToDrawNumber, request.FromDate, request.ToDate)
+	if err != nil {
+		return response, err
+	}
+
+	bestHits := request.BestHits
+	if bestHits <= 0 {
+		bestHits = defaultBestHits
+	}
+
+	// Bets are read by the latest rules, which allow the widest range of
+	// numbers, and checked against the rules of each draw.
+	type skipKey struct {
+		index   int
+		ruleset string
+	}
+	skipped := make(map[skipKey]int)
+
+	response.BestHits = []BacktestHit{}
+	for _, d := range draws {
+		drawRules, err := rulesForDraw(d)
+		if err != nil {
+			return response, err
+		}
+
+		draw := d.TotoDraw
+		draw.PrizeStructure = drawRules.PrizeStructure
+		opts := checkOptions{MaxNumber: drawRules.MaxNumber}
+		masked := newMaskedDraw(draw)
+
+		if draw.GroupPrizes == nil {
+			response.DrawsWithoutGroupPrizes++
+		}
+
+		for i, bet := range bets {
+			if bet == nil {
+				continue
+			}
+
+			if err := drawRules.ValidateBet(bet); err != nil {
+				if request.Strict {
+					return BacktestResponse{}, apperror.Errorf(apperror.CodeOf(err), "bet %d in draw %d: %s", i, d.DrawNumber, err.Error())
+				}
+
+				key := skipKey{i, drawRules.Name}
+				j, ok := skipped[key]
+				if !ok {
+					j = len(response.Skipped)
+					skipped[key] = j
+					response.Skipped = append(response.Skipped, BacktestSkip{
+						Index:   i,
+						Input:   string(request.Bets[i]),
+						Ruleset: drawRules.Name,
+						Reason:  err.Error(),
+					})
+				}
+				response.Skipped[j].Draws++
+				continue
+			}
+
+			result := matchTotoDrawWithBet(masked, bet, opts)
+			result.Index = i
+
+			response.CostCents += result.CostCents
+			response.Prizes = response.Prizes.Add(result.PrizeDetail)
+			response.FixedWinningsCents += result.PrizeDetail.FixedCents
+			if result.PayoutCents != nil {
+				response.WinningsCents += *result.PayoutCents
+			} else {
+				response.WinningsCents += result.PrizeDetail.FixedCents
+			}
+
+			if !result.PrizeDetail.Won {
+				continue
+			}
+
+			response.WinningBets++
+			response.BestHits = addBestHit(response.BestHits, BacktestHit{
+				DrawNumber: d.DrawNumber,
+				DrawDate:   d.DrawDate,
+				BetResult:  result,
+			}, bestHits)
+		}
+	}
+
+	response.Draws = len(draws)
+	if len(draws) > 0 {
+		response.FromDrawNumber = draws[0].DrawNumber
+		response.ToDrawNumber = draws[len(draws)-1].DrawNumber
+	}
+	response.NetCents = response.WinningsCents - response.CostCents
+	return response, nil
+}
+
+// drawsInRange returns the stored draws within the inclusive range. Zero
+// draw numbers and empty dates leave that end of the range open.
+func drawsInRange(fromDrawNumber int, toDrawNumber int, fromDate string, toDate string) ([]drawstore.Draw, error) {
+	for _, date := range []string{fromDate, toDate} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(drawstore.DateLayout, date); err != nil {
+			return nil, apperror.Errorf(apperror.InvalidDrawDate, "unable to parse draw date %s", date)
+		}
+	}
+
+	draws, err := drawStore.Draws()
+	if err != nil {
+		return nil, err
+	}
+
+	var inRange []drawstore.Draw
+	for _, d := range draws {
+		switch {
+		case fromDrawNumber != 0 && d.DrawNumber < fromDrawNumber:
+		case toDrawNumber != 0 && d.DrawNumber > toDrawNumber:
+		case fromDate != "" && d.DrawDate < fromDate:
+		case toDate != "" && d.DrawDate > toDate:
+		default:
+			inRange = append(inRange, d)
+		}
+	}
+	return inRange, nil
+}
+
+// rulesForDraw returns the ruleset in force on the date of a stored draw.
+func rulesForDraw(d drawstore.Draw) (ruleset.Ruleset, error) {
+	date, err := time.Parse(drawstore.DateLayout, d.DrawDate)
+	if err != nil {
+		return ruleset.Ruleset{}, apperror.Errorf(apperror.InvalidDrawDate, "draw %d: unable to parse draw date %s", d.DrawNumber, d.DrawDate)
+	}
+	return ruleset.Default.ForDate(date)
+}
+
+// addBestHit adds a hit to the best hits, which are kept best first and at
+// most max long.
+func addBestHit(hits []BacktestHit, hit BacktestHit, max int) []BacktestHit {
+	i := sort.Search(len(hits), func(i int) bool {
+		return betterHit(hit, hits[i])
+	})
+
+	if i >= max {
+		return hits
+	}
+
+	hits = append(hits, BacktestHit{})
+	copy(hits[i+1:], hits[i:])
+	hits[i] = hit
+
+	if len(hits) > max {
+		hits = hits[:max]
+	}
+	return hits
+}
+
+// betterHit reports whether a is a better hit than b: it wins more shares of
+// a higher group or, failing that, more fixed prize money. Ties go to the
+// earlier draw.
+func betterHit(a BacktestHit, b BacktestHit) bool {
+	pa, pb := a.PrizeDetail, b.PrizeDetail
+	ga := []int{pa.Group1, pa.Group2, pa.Group3, pa.Group4, pa.Group5, pa.Group6, pa.Group7, pa.FixedCents}
+	gb := []int{pb.Group1, pb.Group2, pb.Group3, pb.Group4, pb.Group5, pb.Group6, pb.Group7, pb.FixedCents}
+
+	for i := range ga {
+		if ga[i] != gb[i] {
+			return ga[i] > gb[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+func useBacktestDraws(t *testing.T) {
+	useDrawStore(t,
+		drawstore.Draw{
+			DrawNumber: 2000,
+			DrawDate:   "2008-01-03",
+			TotoDraw: totodraw.TotoDraw{
+				WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 40, 41, 42},
+				AdditionalNumber: 43,
+			},
+		},
+		drawstore.Draw{
+			DrawNumber: 3912,
+			DrawDate:   "2024-10-14",
+			TotoDraw: totodraw.TotoDraw{
+				WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 4, 5, 6},
+				AdditionalNumber: 7,
+				GroupPrizes:      &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000},
+			},
+		},
+		drawstore.Draw{
+			DrawNumber: 3913,
+			DrawDate:   "2024-10-17",
+			TotoDraw: totodraw.TotoDraw{
+				WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 4, 11, 12},
+				AdditionalNumber: 13,
+			},
+		},
+	)
+}
+
+func TestBacktest(t *testing.T) {
+	useBacktestDraws(t)
+
+	response, err := backtest(BacktestRequest{Bets: []NumbersInput{"1 2 3 4 5 7", "20 21 22 23 24 25 26"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Draws != 3 || response.FromDrawNumber != 2000 || response.ToDrawNumber != 3913 {
+		t.Errorf("expected draws 2000 to 3913 but got %+v", response)
+	}
+
+	// Draw 2000, legacy: 3 numbers, $10. Draw 3912: Group 2. Draw 3913:
+	// 4 numbers, Group 5.
+	expectedPrizes := prizetable.Prize{Group2: 1, Group5: 1, FixedCents: 6000, Won: true}
+	if response.Prizes != expectedPrizes {
+		t.Errorf("expected prizes %+v but got %+v", expectedPrizes, response.Prizes)
+	}
+
+	if response.WinningBets != 3 || response.FixedWinningsCents != 6000 || response.WinningsCents != 5006000 {
+		t.Errorf("unexpected winnings %+v", response)
+	}
+
+	if response.DrawsWithoutGroupPrizes != 2 {
+		t.Errorf("expected 2 draws without group prizes but got %d", response.DrawsWithoutGroupPrizes)
+	}
+
+	expectedCost := 3 * (1 + 7) * 100
+	if response.CostCents != expectedCost || response.NetCents != 5006000-expectedCost {
+		t.Errorf("expected cost %d but got %+v", expectedCost, response)
+	}
+
+	expectedBest := []int{3912, 3913, 2000}
+	if len(response.BestHits) != len(expectedBest) {
+		t.Fatalf("expected %d best hits but got %+v", len(expectedBest), response.BestHits)
+	}
+
+	for i, drawNumber := range expectedBest {
+		if response.BestHits[i].DrawNumber != drawNumber {
+			t.Errorf("expected best hit %d in draw %d but got %+v", i, drawNumber, response.BestHits[i])
+		}
+	}
+}
+
+func TestBacktestRange(t *testing.T) {
+	useBacktestDraws(t)
+
+	requests := []BacktestRequest{
+		{Bets: []NumbersInput{"1 2 3 4 5 7"}, FromDrawNumber: 3912, BestHits: 1},
+		{Bets: []NumbersInput{"1 2 3 4 5 7"}, FromDate: "2014-10-07", BestHits: 1},
+		{Bets: []NumbersInput{"1 2 3 4 5 7"}, FromDrawNumber: 3000, ToDate: "2024-12-31", BestHits: 1},
+	}
+
+	for _, request := range requests {
+		response, err := backtest(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if response.Draws != 2 || len(response.BestHits) != 1 || response.BestHits[0].DrawNumber != 3912 {
+			t.Errorf("expected draws 3912 and 3913 with the best hit in 3912 for %+v but got %+v", request, response)
+		}
+	}
+}
+
+func TestBacktestInvalidDate(t *testing.T) {
+	useBacktestDraws(t)
+
+	_, err := backtest(BacktestRequest{Bets: []NumbersInput{"1 2 3 4 5 7"}, ToDate: "31/12/2024"})
+	if apperror.CodeOf(err) != apperror.InvalidDrawDate {
+		t.Errorf("expected %s but got %v", apperror.InvalidDrawDate, err)
+	}
+}
+
+func TestBacktestSkipsBetsOutsideDrawRules(t *testing.T) {
+	useBacktestDraws(t)
+
+	// Draw 2000 is a 6/45 draw, where 46 cannot be played.
+	request := BacktestRequest{Bets: []NumbersInput{"1 2 3 40 41 46", "1 2 3 4 46 R"}}
+
+	response, err := backtest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(response.Skipped) != 2 {
+		t.Fatalf("expected both bets to be skipped in draw 2000 but got %+v", response.Skipped)
+	}
+
+	for i, skip := range response.Skipped {
+		if skip.Index != i || skip.Ruleset != "6/45" || skip.Draws != 1 || skip.Reason != "number not within range: 46" {
+			t.Errorf("unexpected skip %+v", skip)
+		}
+	}
+
+	// Bet 0 wins nothing in the 6/49 draws, and the System Roll bet is
+	// priced with 44 combinations in each of them.
+	if response.CostCents != 2*100+2*4400 {
+		t.Errorf("expected the bets to be paid for in 2 draws but got %d", response.CostCents)
+	}
+
+	request.Strict = true
+	_, err = backtest(request)
+	if apperror.CodeOf(err) != apperror.InvalidNumber {
+		t.Errorf("expected %s in strict mode but got %v", apperror.InvalidNumber, err)
+	}
+}
+
+func TestEndpointBacktest(t *testing.T) {
+	useBacktestDraws(t)
+
+	reader := bytes.NewReader([]byte(`{"bets": ["1 2 3 4 5 7", "1 2 3"]}`))
+	req := httptest.NewRequest(http.MethodPost, "/backtest", reader)
+	w := httptest.NewRecorder()
+	jsonHandler(backtest)(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %d got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response BacktestResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if response.Draws != 3 || len(response.Errors) != 1 || response.Errors[0].Index != 1 {
+		t.Errorf("expected 3 draws and an error for bet 1 but got %+v", response)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// checkBatchSize is how many bets a worker checks at a time, small enough to
+// spread a few hundred System 12 bets with breakdowns across the workers.
+const checkBatchSize = 16
+
+// checkBets matches the bets against the draw on up to checkWorkers
+// goroutines. The results are in the order of the bets, leaving out nil
+// bets, and carry the label of the same index. It stops with a Canceled
+// error once ctx is done.
+func checkBets(ctx context.Context, draw totodraw.TotoDraw, bets []totodraw.Bet, labels []string, opts checkOptions) ([]totodraw.BetResult, error) {
+	results := make([]totodraw.BetResult, len(bets))
+	masked := newMaskedDraw(draw)
+
+	workers := checkWorkers
+	if batches := (len(bets) + checkBatchSize - 1) / checkBatchSize; workers > batches {
+		workers = batches
+	}
+
+	starts := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := start + checkBatchSize
+				if end > len(bets) {
+					end = len(bets)
+				}
+
+				for i := start; i < end; i++ {
+					if bets[i] == nil {
+						continue
+					}
+
+					results[i] = matchTotoDrawWithBet(masked, bets[i], opts)
+					results[i].Index = i
+					results[i].Label = labels[i]
+				}
+			}
+		}()
+	}
+
+	var err error
+	for start := 0; start < len(bets); start += checkBatchSize {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case starts <- start:
+		}
+	}
+	close(starts)
+	wg.Wait()
+
+	if err != nil {
+		return nil, apperror.Errorf(apperror.Canceled, "check stopped: %v", err)
+	}
+
+	checked := make([]totodraw.BetResult, 0, len(bets))
+	for i, bet := range bets {
+		if bet != nil {
+			checked = append(checked, results[i])
+		}
+	}
+	return checked, nil
+}
+
+// checkWorkLimit turns away checks of more than maxCheckCombinations
+// combinations over the given number of draws. A bet is matched as a whole
+// and counts once, unless a breakdown of its ordinary combinations is asked
+// for.
+func checkWorkLimit(bets []totodraw.Bet, draws int, opts checkOptions) error {
+	combinations := 0
+	for _, bet := range bets {
+		switch {
+		case bet == nil:
+		case opts.Breakdown:
+			combinations += bet.Combinations(opts.maxNumber())
+		default:
+			combinations++
+		}
+	}
+
+	if draws > 1 {
+		combinations *= draws
+	}
+
+	if combinations > maxCheckCombinations {
+		return apperror.Errorf(apperror.RequestTooLarge, "request checks %d combinations, more than the limit of %d", combinations, maxCheckCombinations)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+func useCheckLimits(t *testing.T, workers int, combinations int) {
+	t.Helper()
+
+	previousWorkers, previousCombinations := checkWorkers, maxCheckCombinations
+	checkWorkers, maxCheckCombinations = workers, combinations
+	t.Cleanup(func() {
+		checkWorkers, maxCheckCombinations = previousWorkers, previousCombinations
+	})
+}
+
+// manyBetsRequest has n bets of every size, with an unreadable bet every
+// seventh bet.
+func manyBetsRequest(n int) Request {
+	request := Request{
+		WinningNumbers:   "3 9 28 32 37 46",
+		AdditionalNumber: "7",
+		GroupPrizes:      &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000},
+		Breakdown:        true,
+	}
+
+	for i := 0; i < n; i++ {
+		var numbers []string
+		for j := 0; j < 6+i%7; j++ {
+			numbers = append(numbers, fmt.Sprint(1+(i+j*7)%49))
+		}
+
+		bet := strings.Join(numbers, " ")
+		if i%7 == 3 {
+			bet = "1 2 3"
+		}
+		request.Bets = append(request.Bets, NumbersInput(bet))
+	}
+	return request
+}
+
+func TestCheckConcurrentKeepsOrder(t *testing.T) {
+	request := manyBetsRequest(500)
+
+	useCheckLimits(t, 1, defaultMaxCheckCombinations)
+	expected, err := lambdaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	useCheckLimits(t, 8, defaultMaxCheckCombinations)
+	actual, err := lambdaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the same response from 8 workers as from 1")
+	}
+
+	for i := 1; i < len(actual.Results); i++ {
+		if actual.Results[i].Index <= actual.Results[i-1].Index {
+			t.Fatalf("expected results in the order of the bets, got index %d after %d", actual.Results[i].Index, actual.Results[i-1].Index)
+		}
+	}
+
+	if len(actual.Results)+len(actual.Errors) != len(request.Bets) {
+		t.Errorf("expected %d results and errors but got %d and %d", len(request.Bets), len(actual.Results), len(actual.Errors))
+	}
+}
+
+func TestCheckCanceled(t *testing.T) {
+	useCheckLimits(t, 4, defaultMaxCheckCombinations)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := lambdaHandler(ctx, manyBetsRequest(100))
+	if apperror.CodeOf(err) != apperror.Canceled {
+		t.Errorf("expected code %s but got %v", apperror.Canceled, err)
+	}
+}
+
+func TestCheckWorkLimit(t *testing.T) {
+	useCheckLimits(t, 4, 924*2)
+
+	request := Request{
+		WinningNumbers:   "3 9 28 32 37 46",
+		AdditionalNumber: "7",
+		Bets:             []NumbersInput{"1 2 3 4 5 6 7 8 9 10 11 12", "1 2 3 4 5 6 7 8 9 10 11 12", "1 2 3 4 5 6"},
+	}
+
+	// Without a breakdown every bet is a single lookup.
+	if _, err := lambdaHandler(context.Background(), request); err != nil {
+		t.Fatalf("expected 3 bets to be within the limit but got %v", err)
+	}
+
+	request.Breakdown = true
+	request.Bets = request.Bets[:2]
+	if _, err := lambdaHandler(context.Background(), request); err != nil {
+		t.Fatalf("expected the bets within the limit to be checked but got %v", err)
+	}
+
+	request.Bets = append(request.Bets, "1 2 3 4 5 6")
+	_, err := lambdaHandler(context.Background(), request)
+	if apperror.CodeOf(err) != apperror.RequestTooLarge {
+		t.Errorf("expected code %s but got %v", apperror.RequestTooLarge, err)
+	}
+
+	expectedMessage := "request checks 1849 combinations, more than the limit of 1848"
+	if err != nil && err.Error() != expectedMessage {
+		t.Errorf("expected message %q but got %q", expectedMessage, err.Error())
+	}
+}
+
+func TestCheckWorkLimitCountsDraws(t *testing.T) {
+	useBacktestDraws(t)
+	useCheckLimits(t, 4, 14)
+
+	request := Request{DrawNumber: 3912, DrawCount: 2, Bets: []NumbersInput{"1 2 3 4 5 6 7"}, Breakdown: true}
+	if _, err := lambdaHandler(context.Background(), request); err != nil {
+		t.Fatalf("expected 14 combinations to be within the limit but got %v", err)
+	}
+
+	request.DrawCount = 3
+	_, err := lambdaHandler(context.Background(), request)
+	if apperror.CodeOf(err) != apperror.RequestTooLarge {
+		t.Errorf("expected code %s but got %v", apperror.RequestTooLarge, err)
+	}
+}
+
+func TestEndpointRequestTooLarge(t *testing.T) {
+	useCheckLimits(t, 4, 100)
+
+	body := `{"winningNumbers": "3 9 28 32 37 46", "additionalNumber": "7", "bets": ["1 2 3 4 5 6 7 8 9 10 11 12"], "breakdown": true}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status: %d got %d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawimport"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+)
+
+const cliUsage = `usage: totoprizecheck <command> [arguments]
+
+commands:
+  check [file]      check the request in file, or stdin, and print the response
+  backtest [file]   backtest the bets of the request in file, or stdin, against
+                    the stored draws
+  stats [file]      compute number statistics over the stored draws selected
+                    by the request in file, or stdin
+  probability [file]
+                    print the odds of the bet type in the request in file,
+                    or stdin
+  ev [file]         print the expected return of the bet type in the request
+                    in file, or stdin
+  tally [-drawNumber n] [-drawDate date] [-winningNumbers numbers]
+        [-additionalNumber n] [-prizeStructure name] [file]
+                    count the winners among the bets in file, or stdin, one
+                    per line
+  import [file...]  import draw results from CSV or HTML archives, or stdin,
+                    into DRAW_STORE_FILE
+`
+
+// runCLI runs a command line command and returns the exit code. Errors are
+// printed with their error code, and the exit code follows from it.
+func runCLI(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var err error
+	switch args[0] {
+	case "check":
+		err = runCheck(args[1:], stdin, stdout)
+	case "backtest":
+		err = runJSON(args[1:], stdin, stdout, backtest)
+	case "stats":
+		err = runJSON(args[1:], stdin, stdout, numberStats)
+	case "probability":
+		err = runJSON(args[1:], stdin, stdout, odds)
+	case "ev":
+		err = runJSON(args[1:], stdin, stdout, expectedValue)
+	case "tally":
+		err = runTally(args[1:], stdin, stdout)
+	case "import":
+		err = runImport(args[1:], stdin, stdout)
+	default:
+		err = apperror.Errorf(apperror.Usage, "unknown command: %s", args[0])
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %s: %s\n", apperror.CodeOf(err), err.Error())
+		if apperror.CodeOf(err) == apperror.Usage {
+			fmt.Fprint(stderr, cliUsage)
+		}
+		return apperror.ExitCode(err)
+	}
+
+	return 0
+}
+
+// openInput opens the file named by the only argument, or returns stdin when
+// there is none.
+func openInput(args []string, stdin io.Reader) (io.ReadCloser, error) {
+	switch len(args) {
+	case 0:
+		return io.NopCloser(stdin), nil
+	case 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, apperror.Errorf(apperror.Usage, "unable to open %s", args[0])
+		}
+		return f, nil
+	}
+
+	return nil, apperror.New(apperror.Usage, "too many arguments")
+}
+
+func runCheck(args []string, stdin io.Reader, stdout io.Writer) error {
+	return runJSON(args, stdin, stdout, func(request Request) (Response, error) {
+		return lambdaHandler(context.Background(), request)
+	})
+}
+
+// runJSON reads a JSON request from the file named by args, or stdin, and
+// prints the response of f.
+func runJSON[Req any, Res any](args []string, stdin io.Reader, stdout io.Writer, f func(Req) (Res, error)) error {
+	input, err := openInput(args, stdin)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var request Req
+	if err := json.NewDecoder(input).Decode(&request); err != nil {
+		return decodeError(err, "error parsing request")
+	}
+
+	res, err := f(request)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
+
+// runImport imports the draws of every archive into the draw store and
+// prints the import report. Draws that could not be imported make it fail
+// once the rest have been imported.
+func runImport(args []string, stdin io.Reader, stdout io.Writer) error {
+	if _, ok := drawStore.(*drawstore.File); !ok {
+		return apperror.New(apperror.Usage, "DRAW_STORE_FILE is not set")
+	}
+
+	var records []drawimport.Record
+	if len(args) == 0 {
+		r, err := drawimport.Read(stdin)
+		if err != nil {
+			return err
+		}
+		records = r
+	}
+
+	for _, name := range args {
+		input, err := openInput([]string{name}, stdin)
+		if err != nil {
+			return err
+		}
+
+		r, err := drawimport.Read(input)
+		input.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		for i := range r {
+			r[i].Source = name + ": " + r[i].Source
+		}
+		records = append(records, r...)
+	}
+
+	report := drawimport.Import(drawStore, records)
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if len(report.Problems) > 0 {
+		return apperror.Errorf(report.Problems[0].Code, "%d draws could not be imported, first: %s: %s", len(report.Problems), report.Problems[0].Source, report.Problems[0].Message)
+	}
+	return nil
+}
+
+// runTally tallies the bets in the file named by args, or stdin, against the
+// draw given by the flags.
+func runTally(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("tally", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	values := make(map[string]*string)
+	for _, name := range []string{"drawNumber", "drawDate", "winningNumbers", "additionalNumber", "prizeStructure"} {
+		values[name] = flags.String(name, "", "")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return apperror.Errorf(apperror.Usage, "tally: %s", err.Error())
+	}
+
+	request, err := tallyRequest(func(name string) string {
+		return *values[name]
+	})
+	if err != nil {
+		return err
+	}
+
+	input, err := openInput(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	res, err := tally(request, input)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/drawimport"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+)
+
+func TestCLICheck(t *testing.T) {
+	stdin := strings.NewReader(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 6"]}`)
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runCLI([]string{"check"}, stdin, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 but got %d instead: %s", exitCode, stderr.String())
+	}
+
+	var response Response
+	if err := json.NewDecoder(&stdout).Decode(&response); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrize := "Group 1"
+	actualPrize := response.Results[0].Prize
+
+	if expectedPrize != actualPrize {
+		t.Errorf("expected prize: %s but got %s instead", expectedPrize, actualPrize)
+	}
+}
+
+func TestCLICheckInvalidNumber(t *testing.T) {
+	stdin := strings.NewReader(`{"winningNumbers": "01 02 03 04 05 50", "additionalNumber": "07"}`)
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runCLI([]string{"check"}, stdin, &stdout, &stderr)
+
+	expectedExitCode := 65
+	if exitCode != expectedExitCode {
+		t.Errorf("expected exit code %d but got %d instead", expectedExitCode, exitCode)
+	}
+
+	expectedError := "error: INVALID_NUMBER: number not within range: 50 at column 16: [01 02 03 04 05 50]\n"
+	if stderr.String() != expectedError {
+		t.Errorf("expected error %q but got %q instead", expectedError, stderr.String())
+	}
+}
+
+func TestCLIUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runCLI([]string{"frobnicate"}, strings.NewReader(""), &stdout, &stderr)
+
+	expectedExitCode := 64
+	if exitCode != expectedExitCode {
+		t.Errorf("expected exit code %d but got %d instead", expectedExitCode, exitCode)
+	}
+}
+
+func TestCLIImport(t *testing.T) {
+	f, err := drawstore.OpenFile(filepath.Join(t.TempDir(), "draws.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := drawStore
+	drawStore = f
+	t.Cleanup(func() { drawStore = previous })
+
+	archive := "Draw,Date,Winning Number 1,2,3,4,5,6,Additional Number\n3912,14-Oct-24,3,9,28,32,37,46,7\n"
+
+	for _, expectedAdded := range []int{1, 0} {
+		var stdout, stderr bytes.Buffer
+
+		exitCode := runCLI([]string{"import"}, strings.NewReader(archive), &stdout, &stderr)
+		if exitCode != 0 {
+			t.Fatalf("expected exit code 0 but got %d instead: %s", exitCode, stderr.String())
+		}
+
+		var report drawimport.Report
+		if err := json.NewDecoder(&stdout).Decode(&report); err != nil {
+			t.Fatalf("expected error to be nil got %v", err)
+		}
+
+		if report.Added != expectedAdded {
+			t.Errorf("expected %d draws added but got %+v instead", expectedAdded, report)
+		}
+	}
+
+	if _, err := drawStore.ByNumber(3912); err != nil {
+		t.Errorf("expected draw 3912 to be stored but got %v", err)
+	}
+}
+
+func TestCLIImportWithoutDrawStore(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	exitCode := runCLI([]string{"import"}, strings.NewReader(""), &stdout, &stderr)
+
+	expectedExitCode := 64
+	if exitCode != expectedExitCode {
+		t.Errorf("expected exit code %d but got %d instead", expectedExitCode, exitCode)
+	}
+}
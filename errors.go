@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aws/aws-lambda-go/lambda/messages"
+)
+
+type ErrorResponseBody struct {
+	Status  int           `json:"status"`
+	Code    apperror.Code `json:"code"`
+	Message string        `json:"message"`
+}
+
+func newErrorResponseBody(err error) ErrorResponseBody {
+	return ErrorResponseBody{
+		Status:  apperror.HTTPStatus(err),
+		Code:    apperror.CodeOf(err),
+		Message: err.Error(),
+	}
+}
+
+// decodeError is the error reported for a request that could not be decoded.
+// Errors with a code, such as a number out of range in a JSON array, keep it.
+func decodeError(err error, message string) error {
+	var e *apperror.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return apperror.New(apperror.InvalidRequest, message)
+}
+
+func writeErrorHttp(w http.ResponseWriter, err error) {
+	errorResponseBody := newErrorResponseBody(err)
+	w.WriteHeader(errorResponseBody.Status)
+	json.NewEncoder(w).Encode(errorResponseBody)
+}
+
+func lambdaError(err error) error {
+	return messages.InvokeResponse_Error{
+		Message: err.Error(),
+		Type:    string(apperror.CodeOf(err)),
+	}
+}
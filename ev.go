@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/ev"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/probability"
+)
+
+// ExpectedValueRequest asks for the expected return of a bet type, or of
+// every bet type when BetType is empty. GroupPrizes holds the estimated
+// amount of a winning share of each group, the jackpot being Group 1;
+// without it only the fixed prizes count.
+type ExpectedValueRequest struct {
+	BetType        string                  `json:"betType,omitempty"`
+	GroupPrizes    *prizetable.GroupPrizes `json:"groupPrizes,omitempty"`
+	DrawDate       string                  `json:"drawDate,omitempty"`
+	PrizeStructure string                  `json:"prizeStructure,omitempty"`
+}
+
+type ExpectedValueResponse struct {
+	Ruleset        string        `json:"ruleset"`
+	PrizeStructure string        `json:"prizeStructure"`
+	Analyses       []ev.Analysis `json:"analyses"`
+}
+
+func expectedValue(request ExpectedValueRequest) (ExpectedValueResponse, error) {
+	var response ExpectedValueResponse
+
+	var groupPrizes prizetable.GroupPrizes
+	if request.GroupPrizes != nil {
+		if !request.GroupPrizes.IsValid() {
+			return response, apperror.New(apperror.InvalidGroupPrizes, "group prizes should not be negative")
+		}
+		groupPrizes = *request.GroupPrizes
+	}
+
+	rules, table, structure, err := gameRules(request.DrawDate, request.PrizeStructure)
+	if err != nil {
+		return response, err
+	}
+
+	betTypes := []string{request.BetType}
+	if request.BetType == "" {
+		betTypes = ruleBetTypes(rules)
+	}
+
+	for _, betType := range betTypes {
+		bet, err := probability.ExampleBet(betType)
+		if err != nil {
+			return response, err
+		}
+
+		odds := probability.ForBet(table, bet, rules.MaxNumber)
+		cost := bet.CostCents(rules.MaxNumber, unitPriceCents)
+		response.Analyses = append(response.Analyses, ev.Analyze(odds, groupPrizes, cost))
+	}
+
+	response.Ruleset = rules.Name
+	response.PrizeStructure = structure
+	return response, nil
+}
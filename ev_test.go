@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+func TestExpectedValue(t *testing.T) {
+	response, err := expectedValue(ExpectedValueRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(response.Analyses) != 8 {
+		t.Fatalf("expected 8 bet types but got %d", len(response.Analyses))
+	}
+
+	expectedCosts := map[string]int{"Ordinary": 100, "System 12": 92400, "System Roll": 4400}
+	for _, a := range response.Analyses {
+		if cost, ok := expectedCosts[a.BetType]; ok && a.CostCents != cost {
+			t.Errorf("expected %s to cost %d but got %d instead", a.BetType, cost, a.CostCents)
+		}
+
+		if a.ReturnToPlayer <= 0 || a.ReturnToPlayer >= 1 || a.BreakEvenJackpotCents <= 0 {
+			t.Errorf("expected a fixed prize return below 1 and a break-even jackpot but got %+v", a)
+		}
+	}
+}
+
+func TestExpectedValueSystemMatchesOrdinary(t *testing.T) {
+	groupPrizes := &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000}
+
+	ordinary, err := expectedValue(ExpectedValueRequest{BetType: "Ordinary", GroupPrizes: groupPrizes})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	system, err := expectedValue(ExpectedValueRequest{BetType: "System 9", GroupPrizes: groupPrizes})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A System 9 bet is 84 ordinary bets, so it returns the same share of
+	// its cost.
+	o, s := ordinary.Analyses[0], system.Analyses[0]
+	if diff := o.ReturnToPlayer - s.ReturnToPlayer; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("expected the same return to player but got %f and %f", o.ReturnToPlayer, s.ReturnToPlayer)
+	}
+}
+
+func TestExpectedValueInvalidGroupPrizes(t *testing.T) {
+	_, err := expectedValue(ExpectedValueRequest{GroupPrizes: &prizetable.GroupPrizes{Group1: -1}})
+	if apperror.CodeOf(err) != apperror.InvalidGroupPrizes {
+		t.Errorf("expected %s but got %v", apperror.InvalidGroupPrizes, err)
+	}
+}
+
+func TestCLIExpectedValue(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := runCLI([]string{"ev"}, strings.NewReader(`{"betType": "System Roll"}`), &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 but got %d instead: %s", exitCode, stderr.String())
+	}
+
+	var response ExpectedValueResponse
+	if err := json.NewDecoder(&stdout).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if len(response.Analyses) != 1 || response.Analyses[0].BetType != "System Roll" {
+		t.Errorf("expected the System Roll analysis but got %+v", response.Analyses)
+	}
+}
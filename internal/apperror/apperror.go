@@ -0,0 +1,117 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code identifies a kind of error. Codes are part of the API and must not
+// change once published.
+type Code string
+
+const (
+	InvalidRequest        Code = "INVALID_REQUEST"
+	InvalidNumber         Code = "INVALID_NUMBER"
+	DuplicateNumber       Code = "DUPLICATE_NUMBER"
+	BadBetSize            Code = "BAD_BET_SIZE"
+	BadWinningNumbers     Code = "BAD_WINNING_NUMBERS"
+	InvalidDrawDate       Code = "INVALID_DRAW_DATE"
+	UnknownPrizeStructure Code = "UNKNOWN_PRIZE_STRUCTURE"
+	InvalidGroupPrizes    Code = "INVALID_GROUP_PRIZES"
+	InvalidTicket         Code = "INVALID_TICKET"
+	InvalidDraw           Code = "INVALID_DRAW"
+	DrawNotFound          Code = "DRAW_NOT_FOUND"
+	DrawConflict          Code = "DRAW_CONFLICT"
+	RequestTooLarge       Code = "REQUEST_TOO_LARGE"
+	Canceled              Code = "CANCELED"
+	MethodNotAllowed      Code = "METHOD_NOT_ALLOWED"
+	Usage                 Code = "USAGE"
+	Internal              Code = "INTERNAL"
+)
+
+// Exit codes follow the BSD sysexits convention.
+const (
+	exitUsage    = 64
+	exitDataErr  = 65
+	exitNoInput  = 66
+	exitSoftware = 70
+	exitTempFail = 75
+)
+
+type class struct {
+	httpStatus int
+	exitCode   int
+}
+
+var (
+	invalidInput = class{http.StatusBadRequest, exitDataErr}
+	usage        = class{http.StatusBadRequest, exitUsage}
+	internal     = class{http.StatusInternalServerError, exitSoftware}
+)
+
+var classes = map[Code]class{
+	InvalidRequest:        invalidInput,
+	InvalidNumber:         invalidInput,
+	DuplicateNumber:       invalidInput,
+	BadBetSize:            invalidInput,
+	BadWinningNumbers:     invalidInput,
+	InvalidDrawDate:       invalidInput,
+	UnknownPrizeStructure: invalidInput,
+	InvalidGroupPrizes:    invalidInput,
+	InvalidTicket:         invalidInput,
+	InvalidDraw:           invalidInput,
+	DrawNotFound:          {http.StatusNotFound, exitNoInput},
+	DrawConflict:          {http.StatusConflict, exitDataErr},
+	RequestTooLarge:       {http.StatusRequestEntityTooLarge, exitDataErr},
+	Canceled:              {http.StatusServiceUnavailable, exitTempFail},
+	MethodNotAllowed:      {http.StatusMethodNotAllowed, exitUsage},
+	Usage:                 usage,
+	Internal:              internal,
+}
+
+// Error is an error with a stable code. Errors wrapping an Error, with %w,
+// keep its code.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func Errorf(code Code, format string, a ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, a...))
+}
+
+// CodeOf returns the code of the first Error in err's chain, or Internal when
+// there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Internal
+}
+
+// HTTPStatus returns the HTTP status an error should be reported with.
+func HTTPStatus(err error) int {
+	return classOf(err).httpStatus
+}
+
+// ExitCode returns the exit code the CLI should exit with after an error.
+func ExitCode(err error) int {
+	return classOf(err).exitCode
+}
+
+func classOf(err error) class {
+	if c, ok := classes[CodeOf(err)]; ok {
+		return c
+	}
+	return internal
+}
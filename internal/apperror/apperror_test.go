@@ -0,0 +1,65 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCodeOfWrappedError(t *testing.T) {
+	err := fmt.Errorf("invalid bet: %w", New(BadBetSize, "bets should contain 6 to 12 numbers, got 5"))
+
+	expectedCode := BadBetSize
+	actualCode := CodeOf(err)
+	if actualCode != expectedCode {
+		t.Errorf("expecting code: %s, got %s instead", expectedCode, actualCode)
+	}
+
+	expectedMessage := "invalid bet: bets should contain 6 to 12 numbers, got 5"
+	if err.Error() != expectedMessage {
+		t.Errorf("expecting message: %s, got %s instead", expectedMessage, err.Error())
+	}
+}
+
+func TestCodeOfPlainError(t *testing.T) {
+	err := errors.New("disk on fire")
+
+	expectedCode := Internal
+	actualCode := CodeOf(err)
+	if actualCode != expectedCode {
+		t.Errorf("expecting code: %s, got %s instead", expectedCode, actualCode)
+	}
+
+	if HTTPStatus(err) != http.StatusInternalServerError {
+		t.Errorf("expecting status: %d, got %d instead", http.StatusInternalServerError, HTTPStatus(err))
+	}
+
+	if ExitCode(err) != 70 {
+		t.Errorf("expecting exit code: %d, got %d instead", 70, ExitCode(err))
+	}
+}
+
+func TestInvalidInputMapping(t *testing.T) {
+	err := New(DuplicateNumber, "duplicate numbers found: [1 1]")
+
+	if HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("expecting status: %d, got %d instead", http.StatusBadRequest, HTTPStatus(err))
+	}
+
+	if ExitCode(err) != 65 {
+		t.Errorf("expecting exit code: %d, got %d instead", 65, ExitCode(err))
+	}
+}
+
+func TestRequestTooLargeMapping(t *testing.T) {
+	err := New(RequestTooLarge, "request has too many combinations")
+
+	if HTTPStatus(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("expecting status: %d, got %d instead", http.StatusRequestEntityTooLarge, HTTPStatus(err))
+	}
+
+	if ExitCode(err) != 65 {
+		t.Errorf("expecting exit code: %d, got %d instead", 65, ExitCode(err))
+	}
+}
@@ -0,0 +1,217 @@
+package drawimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+var (
+	drawNumberHeaders = map[string]bool{"draw": true, "drawno": true, "drawnumber": true, "drawnum": true}
+	drawDateHeaders   = map[string]bool{"date": true, "drawdate": true}
+	additionalHeaders = map[string]bool{"additional": true, "additionalno": true, "additionalnumber": true, "additionalnum": true, "add": true, "bonus": true}
+	numbersHeaders    = map[string]bool{"winningnumbers": true, "winningno": true, "numbers": true}
+
+	numberHeader = regexp.MustCompile(`^(winningnumber|winningno|winning|number|num|no|win|n)([1-6])$`)
+	groupHeader  = regexp.MustCompile(`^group([1-4])(prize|share|shareamount|amount)?$`)
+)
+
+// csvColumns are the indexes of the columns of a CSV archive. numbers holds
+// either the six winning number columns or a single column with all of
+// them.
+type csvColumns struct {
+	drawNumber int
+	drawDate   int
+	numbers    []int
+	additional int
+	groups     map[int]int
+}
+
+// ReadCSV reads a CSV results archive. The first row names the columns: the
+// draw number, the draw date, the winning numbers, either in six columns or
+// in one, the additional number and, optionally, the Group 1 to Group 4 share
+// amounts in dollars. Other columns are ignored.
+func ReadCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, apperror.New(apperror.InvalidDraw, "unable to read the header of the results archive")
+	}
+
+	columns, err := findColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		// A malformed row is reported on its own and reading goes on with
+		// the next one. Any other error ends the archive.
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			source := fmt.Sprintf("line %d", parseErr.StartLine)
+			records = append(records, Record{Source: source, Err: apperror.Errorf(apperror.InvalidDraw, "unable to read row: %s", parseErr.Err.Error())})
+			continue
+		}
+		if err != nil {
+			return records, apperror.Errorf(apperror.InvalidDraw, "unable to read the results archive: %s", err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("line %d", line)
+
+		if isBlank(row) {
+			continue
+		}
+
+		r := Record{Source: source}
+		r.Draw, r.Err = columns.draw(row)
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func findColumns(header []string) (csvColumns, error) {
+	c := csvColumns{drawNumber: -1, drawDate: -1, additional: -1, groups: make(map[int]int)}
+	numbers := make(map[int]int)
+
+	for i, h := range header {
+		name := normalize(h)
+		switch {
+		case drawNumberHeaders[name]:
+			c.drawNumber = i
+		case drawDateHeaders[name]:
+			c.drawDate = i
+		case additionalHeaders[name]:
+			c.additional = i
+		case numbersHeaders[name]:
+			c.numbers = []int{i}
+		case numberHeader.MatchString(name):
+			numbers[int(name[len(name)-1]-'0')] = i
+		case len(name) == 1 && name >= "2" && name <= "6" && len(numbers) > 0:
+			// Archives often name only the first of the six columns, as in
+			// "Winning Number 1,2,3,4,5,6".
+			numbers[int(name[0]-'0')] = i
+		case groupHeader.MatchString(name):
+			c.groups[int(groupHeader.FindStringSubmatch(name)[1][0]-'0')] = i
+		}
+	}
+
+	if len(numbers) == 6 {
+		c.numbers = nil
+		for n := 1; n <= 6; n++ {
+			c.numbers = append(c.numbers, numbers[n])
+		}
+	}
+
+	var missing []string
+	if c.drawNumber < 0 {
+		missing = append(missing, "draw number")
+	}
+	if c.drawDate < 0 {
+		missing = append(missing, "draw date")
+	}
+	if c.numbers == nil {
+		missing = append(missing, "winning numbers")
+	}
+	if c.additional < 0 {
+		missing = append(missing, "additional number")
+	}
+
+	if len(missing) > 0 {
+		return c, apperror.Errorf(apperror.InvalidDraw, "results archive has no %s column", strings.Join(missing, ", "))
+	}
+	return c, nil
+}
+
+func (c csvColumns) draw(row []string) (drawstore.Draw, error) {
+	field := func(i int) string {
+		if i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	drawNumber, err := parseNumber(field(c.drawNumber))
+	if err != nil {
+		return drawstore.Draw{}, err
+	}
+
+	var texts []string
+	if len(c.numbers) == 1 {
+		texts = strings.FieldsFunc(field(c.numbers[0]), func(r rune) bool {
+			return r == ',' || r == '-' || unicode.IsSpace(r)
+		})
+	} else {
+		for _, i := range c.numbers {
+			texts = append(texts, field(i))
+		}
+	}
+
+	numbers := make([]int, 0, len(texts))
+	for _, t := range texts {
+		n, err := parseNumber(t)
+		if err != nil {
+			return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+		}
+		numbers = append(numbers, n)
+	}
+
+	additional, err := parseNumber(field(c.additional))
+	if err != nil {
+		return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+	}
+
+	var groupPrizes *prizetable.GroupPrizes
+	for group, i := range c.groups {
+		cents, err := parseCents(field(i))
+		if err != nil {
+			return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+		}
+
+		if groupPrizes == nil {
+			groupPrizes = &prizetable.GroupPrizes{}
+		}
+		setGroupPrize(groupPrizes, group, cents)
+	}
+
+	return newDraw(drawNumber, field(c.drawDate), numbers, additional, groupPrizes)
+}
+
+// normalize lowercases a column name and drops everything but letters and
+// digits, so that "Draw No." and "drawNo" are the same.
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isBlank(row []string) bool {
+	for _, f := range row {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
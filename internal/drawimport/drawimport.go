@@ -0,0 +1,210 @@
+package drawimport
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// Record is a draw read from a results archive, or the reason it could not
+// be read. Source locates the draw in the archive, e.g. "line 12".
+type Record struct {
+	Source string
+	Draw   drawstore.Draw
+	Err    error
+}
+
+// Problem is a draw that could not be imported.
+type Problem struct {
+	Source  string        `json:"source"`
+	Code    apperror.Code `json:"code"`
+	Message string        `json:"message"`
+}
+
+// Report counts what an import did to the store. Added draws were new,
+// Updated draws gained group prizes and Unchanged draws were already stored.
+// Duplicates are draws that appear more than once in the imported records.
+type Report struct {
+	Added      int       `json:"added"`
+	Updated    int       `json:"updated"`
+	Unchanged  int       `json:"unchanged"`
+	Duplicates int       `json:"duplicates"`
+	Problems   []Problem `json:"problems,omitempty"`
+}
+
+// Read reads a results archive, which is either a CSV file or a saved HTML
+// results page.
+func Read(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidDraw, "results archive is empty")
+		}
+
+		if !unicode.IsSpace(rune(b[0])) {
+			if b[0] == '<' {
+				return ReadHTML(br)
+			}
+			return ReadCSV(br)
+		}
+
+		br.ReadByte()
+	}
+}
+
+// Import puts the draws of the records into the store. Draws that cannot be
+// read, or that conflict with a stored draw, are reported as problems and
+// the rest are still imported, so an import can be re-run once they are
+// fixed.
+func Import(store drawstore.Store, records []Record) Report {
+	var report Report
+	seen := make(map[int]bool, len(records))
+
+	for _, r := range records {
+		if r.Err != nil {
+			report.problem(r.Source, r.Err)
+			continue
+		}
+
+		n := r.Draw.DrawNumber
+		before, err := store.ByNumber(n)
+		stored := err == nil
+
+		if err := store.Put(r.Draw); err != nil {
+			report.problem(r.Source, err)
+			continue
+		}
+
+		after, _ := store.ByNumber(n)
+		switch {
+		case seen[n]:
+			report.Duplicates++
+		case !stored:
+			report.Added++
+		case reflect.DeepEqual(before, after):
+			report.Unchanged++
+		default:
+			report.Updated++
+		}
+		seen[n] = true
+	}
+
+	return report
+}
+
+func (r *Report) problem(source string, err error) {
+	r.Problems = append(r.Problems, Problem{
+		Source:  source,
+		Code:    apperror.CodeOf(err),
+		Message: err.Error(),
+	})
+}
+
+// newDraw builds a draw and validates it the way the check does, through
+// totodraw.NewTotoDraw.
+func newDraw(drawNumber int, date string, numbers []int, additional int, groupPrizes *prizetable.GroupPrizes) (drawstore.Draw, error) {
+	drawDate, err := parseDate(date)
+	if err != nil {
+		return drawstore.Draw{}, err
+	}
+
+	if len(numbers) != 6 {
+		return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: expected 6 winning numbers, got %d", drawNumber, len(numbers))
+	}
+
+	sort.Ints(numbers)
+	t, err := totodraw.NewTotoDraw(numbers, additional)
+	if err != nil {
+		return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+	}
+	t.GroupPrizes = groupPrizes
+
+	d := drawstore.Draw{DrawNumber: drawNumber, DrawDate: drawDate, TotoDraw: t}
+	if err := d.Validate(); err != nil {
+		return drawstore.Draw{}, err
+	}
+	return d, nil
+}
+
+var dateLayouts = []string{
+	drawstore.DateLayout,
+	"2/1/2006",
+	"2/1/06",
+	"2-Jan-06",
+	"2-Jan-2006",
+	"2 Jan 2006",
+	"Mon, 2 Jan 2006",
+	"Mon 2 Jan 2006",
+}
+
+func parseDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d.Format(drawstore.DateLayout), nil
+		}
+	}
+	return "", apperror.Errorf(apperror.InvalidDraw, "unable to parse draw date %q", s)
+}
+
+func parseNumber(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, apperror.Errorf(apperror.InvalidDraw, "unable to parse number %q", s)
+	}
+	return n, nil
+}
+
+// parseCents reads a dollar amount such as "$1,234,567.50". Blanks and "-",
+// used for groups without winners, are zero.
+func parseCents(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+
+	amount := strings.NewReplacer("$", "", ",", "").Replace(s)
+	dollars, cents, hasCents := strings.Cut(amount, ".")
+	if hasCents && len(cents) != 2 {
+		return 0, apperror.Errorf(apperror.InvalidDraw, "unable to parse amount %q", s)
+	}
+
+	d, err := strconv.Atoi(dollars)
+	if err != nil || d < 0 {
+		return 0, apperror.Errorf(apperror.InvalidDraw, "unable to parse amount %q", s)
+	}
+
+	c := 0
+	if hasCents {
+		if c, err = strconv.Atoi(cents); err != nil || c < 0 {
+			return 0, apperror.Errorf(apperror.InvalidDraw, "unable to parse amount %q", s)
+		}
+	}
+	return d*100 + c, nil
+}
+
+// setGroupPrize sets the share amount of a Group 1 to Group 4 prize.
+func setGroupPrize(g *prizetable.GroupPrizes, group int, cents int) {
+	switch group {
+	case 1:
+		g.Group1 = cents
+	case 2:
+		g.Group2 = cents
+	case 3:
+		g.Group3 = cents
+	case 4:
+		g.Group4 = cents
+	}
+}
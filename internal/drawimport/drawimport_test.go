@@ -0,0 +1,167 @@
+package drawimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+)
+
+const archiveCSV = `Draw,Date,Winning Number 1,2,3,4,5,6,Additional Number,From Last
+3913,17-Oct-24,4,12,19,33,40,44,23,4
+3912,14-Oct-24,3,9,28,32,37,46,7,
+
+3911,10-Oct-24,1,2,3,4,5,5,6,
+3910,7-Oct-24,1,2,3,4,5,6,7,
+`
+
+const resultsHTML = `<html><body>
+<div class='tables-wrap'>
+<table class='table'><thead><tr><th class='drawDate'>Mon, 14 Oct 2024</th><th class='drawNumber'>Draw No. 3912</th></tr></thead></table>
+<table class='table'><tbody><tr><td class='win1'>3</td><td class='win2'>9</td><td class='win3'>28</td><td class='win4'>32</td><td class='win5'>37</td><td class='win6'>46</td></tr></tbody></table>
+<table class='table'><tbody><tr><td class='additional'>7</td></tr></tbody></table>
+<table class='table tableWinningShares'>
+<tr><th>Prize Group</th><th>Share Amount</th><th>No. of Winning Shares</th></tr>
+<tr><td>Group 1</td><td>-</td><td>-</td></tr>
+<tr><td>Group 2</td><td>$67,549</td><td>4</td></tr>
+<tr><td>Group 3</td><td>$1,612</td><td>167</td></tr>
+<tr><td>Group 4</td><td>$414</td><td>337</td></tr>
+<tr><td>Group 5</td><td>$50</td><td>8,090</td></tr>
+</table>
+</div>
+<div class='tables-wrap'>
+<table class='table'><thead><tr><th class='drawDate'>Thu, 10 Oct 2024</th><th class='drawNumber'>Draw No. 3911</th></tr></thead></table>
+<table class='table'><tbody><tr><td class='win1'>1</td><td class='win2'>2</td><td class='win3'>3</td><td class='win4'>4</td><td class='win5'>5</td></tr></tbody></table>
+<table class='table'><tbody><tr><td class='additional'>7</td></tr></tbody></table>
+</div>
+</body></html>`
+
+func TestReadCSV(t *testing.T) {
+	records, err := Read(strings.NewReader(archiveCSV))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("was expecting 4 records but got %+v instead", records)
+	}
+
+	d := records[1].Draw
+	if records[1].Err != nil || d.DrawNumber != 3912 || d.DrawDate != "2024-10-14" || d.WinningNumbers[5] != 46 || d.AdditionalNumber != 7 {
+		t.Errorf("was expecting draw 3912 but got %+v, %v instead", d, records[1].Err)
+	}
+
+	if records[2].Source != "line 5" || apperror.CodeOf(records[2].Err) != apperror.InvalidDraw {
+		t.Errorf("was expecting line 5 to be invalid but got %+v instead", records[2])
+	}
+}
+
+func TestReadCSVSingleNumbersColumnAndGroupPrizes(t *testing.T) {
+	archive := "drawNo,drawDate,winningNumbers,additional,group1,group2,group3,group4\n" +
+		`3912,2024-10-14,"3 9 28 32 37 46",7,-,"$67,549",$1612.00,$414` + "\n"
+
+	records, err := ReadCSV(strings.NewReader(archive))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	d := records[0].Draw
+	if records[0].Err != nil || d.GroupPrizes == nil {
+		t.Fatalf("was expecting draw 3912 with group prizes but got %+v, %v instead", d, records[0].Err)
+	}
+
+	if d.GroupPrizes.Group1 != 0 || d.GroupPrizes.Group2 != 6754900 || d.GroupPrizes.Group3 != 161200 || d.GroupPrizes.Group4 != 41400 {
+		t.Errorf("unexpected group prizes %+v", *d.GroupPrizes)
+	}
+}
+
+func TestReadCSVMalformedRow(t *testing.T) {
+	archive := "draw,date,numbers,additional\n" +
+		"3900,2023-01-02,1 2 3 4 5 6,7\n" +
+		"x\"y,2,3,4\n" +
+		"3901,2023-01-05,1 2 3 4 5 8,9\n"
+
+	records, err := ReadCSV(strings.NewReader(archive))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("was expecting 3 records but got %+v instead", records)
+	}
+
+	if records[1].Source != "line 3" || apperror.CodeOf(records[1].Err) != apperror.InvalidDraw {
+		t.Errorf("was expecting line 3 to be unreadable but got %+v instead", records[1])
+	}
+
+	if records[0].Err != nil || records[2].Err != nil || records[2].Draw.DrawNumber != 3901 {
+		t.Errorf("was expecting the rows around line 3 to be read but got %+v instead", records)
+	}
+}
+
+func TestReadCSVMissingColumns(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("Draw,Date,N1,N2,N3,N4,N5,N6\n"))
+	if err == nil || err.Error() != "results archive has no additional number column" {
+		t.Errorf("was expecting a missing column error but got %v instead", err)
+	}
+}
+
+func TestReadHTML(t *testing.T) {
+	records, err := Read(strings.NewReader("\n  " + resultsHTML))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("was expecting 2 records but got %+v instead", records)
+	}
+
+	d := records[0].Draw
+	if records[0].Err != nil || records[0].Source != "draw 3912" || d.DrawDate != "2024-10-14" || d.AdditionalNumber != 7 {
+		t.Fatalf("was expecting draw 3912 but got %+v, %v instead", records[0], records[0].Err)
+	}
+
+	if d.GroupPrizes == nil || d.GroupPrizes.Group2 != 6754900 || d.GroupPrizes.Group4 != 41400 {
+		t.Errorf("unexpected group prizes %+v", d.GroupPrizes)
+	}
+
+	if apperror.CodeOf(records[1].Err) != apperror.InvalidDraw {
+		t.Errorf("was expecting draw 3911 to be invalid but got %+v instead", records[1])
+	}
+}
+
+func TestImportIdempotent(t *testing.T) {
+	records, _ := ReadCSV(strings.NewReader(archiveCSV))
+	store := &drawstore.Memory{}
+
+	report := Import(store, records)
+	if report.Added != 3 || report.Unchanged != 0 || len(report.Problems) != 1 {
+		t.Errorf("unexpected first import report %+v", report)
+	}
+
+	report = Import(store, records)
+	if report.Added != 0 || report.Unchanged != 3 || len(report.Problems) != 1 {
+		t.Errorf("unexpected second import report %+v", report)
+	}
+
+	htmlRecords, _ := ReadHTML(strings.NewReader(resultsHTML))
+	report = Import(store, htmlRecords)
+	if report.Updated != 1 {
+		t.Errorf("was expecting draw 3912 to gain group prizes but got %+v instead", report)
+	}
+}
+
+func TestImportDuplicatesAndConflicts(t *testing.T) {
+	archive := archiveCSV + "3912,14-Oct-24,3,9,28,32,37,46,7,\n3912,14-Oct-24,3,9,28,32,37,46,8,\n"
+	records, _ := ReadCSV(strings.NewReader(archive))
+
+	report := Import(&drawstore.Memory{}, records)
+	if report.Duplicates != 1 {
+		t.Errorf("was expecting 1 duplicate but got %+v instead", report)
+	}
+
+	if len(report.Problems) != 2 || report.Problems[1].Code != apperror.DrawConflict || report.Problems[1].Source != "line 8" {
+		t.Errorf("was expecting a conflict on line 8 but got %+v instead", report.Problems)
+	}
+}
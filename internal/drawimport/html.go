@@ -0,0 +1,101 @@
+package drawimport
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+var (
+	htmlDrawDate   = regexp.MustCompile(`class=["']drawDate["'][^>]*>([^<]*)<`)
+	htmlDrawNumber = regexp.MustCompile(`class=["']drawNumber["'][^>]*>[^<0-9]*([0-9]+)`)
+	htmlWinning    = regexp.MustCompile(`class=["']win([1-6])["'][^>]*>\s*([^<]*?)\s*<`)
+	htmlAdditional = regexp.MustCompile(`class=["']additional["'][^>]*>\s*([^<]*?)\s*<`)
+	htmlGroupPrize = regexp.MustCompile(`>\s*Group\s*([1-4])\s*</td>\s*<td[^>]*>\s*([^<]*?)\s*<`)
+)
+
+// ReadHTML reads results pages saved from the Singapore Pools website. Every
+// draw on the page starts at its drawDate heading and is followed by the
+// drawNumber heading, the win1 to win6 and additional cells and the table of
+// group share amounts.
+func ReadHTML(r io.Reader) ([]Record, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	page := string(b)
+
+	starts := htmlDrawDate.FindAllStringIndex(page, -1)
+	if len(starts) == 0 {
+		return nil, apperror.New(apperror.InvalidDraw, "no draws found on the results page")
+	}
+
+	records := make([]Record, len(starts))
+	for i, start := range starts {
+		end := len(page)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+
+		records[i] = Record{Source: fmt.Sprintf("result %d", i+1)}
+		records[i].Draw, records[i].Err = htmlDraw(page[start[0]:end])
+		if records[i].Err == nil {
+			records[i].Source = fmt.Sprintf("draw %d", records[i].Draw.DrawNumber)
+		}
+	}
+
+	return records, nil
+}
+
+func htmlDraw(section string) (drawstore.Draw, error) {
+	m := htmlDrawNumber.FindStringSubmatch(section)
+	if m == nil {
+		return drawstore.Draw{}, apperror.New(apperror.InvalidDraw, "result has no draw number")
+	}
+
+	drawNumber, err := parseNumber(m[1])
+	if err != nil {
+		return drawstore.Draw{}, err
+	}
+
+	date := html.UnescapeString(htmlDrawDate.FindStringSubmatch(section)[1])
+
+	var numbers []int
+	for _, m := range htmlWinning.FindAllStringSubmatch(section, -1) {
+		n, err := parseNumber(m[2])
+		if err != nil {
+			return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+		}
+		numbers = append(numbers, n)
+	}
+
+	m = htmlAdditional.FindStringSubmatch(section)
+	if m == nil {
+		return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d has no additional number", drawNumber)
+	}
+
+	additional, err := parseNumber(m[1])
+	if err != nil {
+		return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+	}
+
+	var groupPrizes *prizetable.GroupPrizes
+	for _, m := range htmlGroupPrize.FindAllStringSubmatch(section, -1) {
+		cents, err := parseCents(html.UnescapeString(m[2]))
+		if err != nil {
+			return drawstore.Draw{}, apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", drawNumber, err.Error())
+		}
+
+		if groupPrizes == nil {
+			groupPrizes = &prizetable.GroupPrizes{}
+		}
+		setGroupPrize(groupPrizes, int(m[1][0]-'0'), cents)
+	}
+
+	return newDraw(drawNumber, date, numbers, additional, groupPrizes)
+}
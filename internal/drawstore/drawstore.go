@@ -0,0 +1,189 @@
+package drawstore
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/stringutils"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// DateLayout is the layout of draw dates.
+const DateLayout = "2006-01-02"
+
+// Draw is the result of a past draw. DrawDate is formatted as 2006-01-02.
+type Draw struct {
+	DrawNumber int    `json:"drawNumber"`
+	DrawDate   string `json:"drawDate"`
+	totodraw.TotoDraw
+}
+
+// Store holds the results of past draws.
+type Store interface {
+	// ByNumber returns the draw with the given draw number.
+	ByNumber(drawNumber int) (Draw, error)
+	// ByDate returns the draw held on the given date, formatted as
+	// 2006-01-02.
+	ByDate(date string) (Draw, error)
+	// Draws returns every draw, ordered by draw number.
+	Draws() ([]Draw, error)
+	// Put adds a draw. Putting a draw that is already stored does nothing,
+	// and group prizes may be added to a stored draw that has none. Any other
+	// difference from a stored draw with the same number or date is a
+	// DrawConflict error.
+	Put(d Draw) error
+}
+
+// Validate checks that a draw has a number, a date and 6 distinct winning
+// numbers besides the additional number. The winning numbers are sorted.
+func (d *Draw) Validate() error {
+	if d.DrawNumber < 1 {
+		return apperror.Errorf(apperror.InvalidDraw, "draw number should be positive, got %d", d.DrawNumber)
+	}
+
+	if _, err := time.Parse(DateLayout, d.DrawDate); err != nil {
+		return apperror.Errorf(apperror.InvalidDraw, "draw %d: unable to parse draw date %s", d.DrawNumber, d.DrawDate)
+	}
+
+	if len(d.WinningNumbers) != 6 || !d.WinningNumbers.IsValid() {
+		return apperror.Errorf(apperror.InvalidDraw, "draw %d: winning numbers should contain 6 distinct numbers: %v", d.DrawNumber, d.WinningNumbers)
+	}
+
+	for _, n := range append([]int{d.AdditionalNumber}, d.WinningNumbers...) {
+		if n < stringutils.MinNumber || n > stringutils.MaxNumber {
+			return apperror.Errorf(apperror.InvalidDraw, "draw %d: number not within range: %d", d.DrawNumber, n)
+		}
+	}
+
+	if d.GroupPrizes != nil && !d.GroupPrizes.IsValid() {
+		return apperror.Errorf(apperror.InvalidDraw, "draw %d: group prizes should not be negative", d.DrawNumber)
+	}
+
+	numbers := append(totodraw.WinningNumbers{}, d.WinningNumbers...)
+	sort.Ints(numbers)
+
+	t, err := totodraw.NewTotoDraw(numbers, d.AdditionalNumber)
+	if err != nil {
+		return apperror.Errorf(apperror.InvalidDraw, "draw %d: %s", d.DrawNumber, err.Error())
+	}
+
+	d.WinningNumbers = t.WinningNumbers
+	return nil
+}
+
+// Memory is a Store held in memory. It is safe for concurrent use, and the
+// zero Memory is an empty store.
+type Memory struct {
+	mu       sync.RWMutex
+	byNumber map[int]Draw
+	byDate   map[string]int
+}
+
+// NewMemory returns a Memory store holding the given draws.
+func NewMemory(draws ...Draw) (*Memory, error) {
+	m := &Memory{}
+	for _, d := range draws {
+		if err := m.Put(d); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Memory) ByNumber(drawNumber int) (Draw, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d, ok := m.byNumber[drawNumber]
+	if !ok {
+		return Draw{}, apperror.Errorf(apperror.DrawNotFound, "draw %d not found", drawNumber)
+	}
+	return d, nil
+}
+
+func (m *Memory) ByDate(date string) (Draw, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n, ok := m.byDate[date]
+	if !ok {
+		return Draw{}, apperror.Errorf(apperror.DrawNotFound, "no draw found on %s", date)
+	}
+	return m.byNumber[n], nil
+}
+
+func (m *Memory) Draws() ([]Draw, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	draws := make([]Draw, 0, len(m.byNumber))
+	for _, d := range m.byNumber {
+		draws = append(draws, d)
+	}
+
+	sort.Slice(draws, func(i, j int) bool {
+		return draws[i].DrawNumber < draws[j].DrawNumber
+	})
+	return draws, nil
+}
+
+func (m *Memory) Put(d Draw) error {
+	_, err := m.put(d)
+	return err
+}
+
+// put adds a draw and reports whether the store changed.
+func (m *Memory) put(d Draw) (bool, error) {
+	if err := d.Validate(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.byNumber == nil {
+		m.byNumber = make(map[int]Draw)
+		m.byDate = make(map[string]int)
+	}
+
+	if n, ok := m.byDate[d.DrawDate]; ok && n != d.DrawNumber {
+		return false, apperror.Errorf(apperror.DrawConflict, "draw %d is on %s, which already has draw %d", d.DrawNumber, d.DrawDate, n)
+	}
+
+	stored, ok := m.byNumber[d.DrawNumber]
+	if ok {
+		merged, err := merge(stored, d)
+		if err != nil {
+			return false, err
+		}
+
+		if reflect.DeepEqual(merged, stored) {
+			return false, nil
+		}
+		d = merged
+	}
+
+	m.byNumber[d.DrawNumber] = d
+	m.byDate[d.DrawDate] = d.DrawNumber
+	return true, nil
+}
+
+// merge returns the stored draw with the group prizes of d added, provided
+// the two draws agree on everything else.
+func merge(stored Draw, d Draw) (Draw, error) {
+	if stored.GroupPrizes == nil {
+		stored.GroupPrizes = d.GroupPrizes
+	}
+
+	if d.GroupPrizes == nil {
+		d.GroupPrizes = stored.GroupPrizes
+	}
+
+	if !reflect.DeepEqual(stored, d) {
+		return Draw{}, apperror.Errorf(apperror.DrawConflict, "draw %d conflicts with the stored draw", d.DrawNumber)
+	}
+	return stored, nil
+}
@@ -0,0 +1,160 @@
+package drawstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+func draw3912() Draw {
+	return Draw{
+		DrawNumber: 3912,
+		DrawDate:   "2024-10-14",
+		TotoDraw: totodraw.TotoDraw{
+			WinningNumbers:   totodraw.WinningNumbers{46, 3, 9, 28, 32, 37},
+			AdditionalNumber: 7,
+		},
+	}
+}
+
+func TestMemoryLookup(t *testing.T) {
+	m, err := NewMemory(draw3912())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	d, err := m.ByNumber(3912)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if d.WinningNumbers[0] != 3 || d.WinningNumbers[5] != 46 {
+		t.Errorf("was expecting sorted winning numbers but got %v instead", d.WinningNumbers)
+	}
+
+	d, err = m.ByDate("2024-10-14")
+	if err != nil || d.DrawNumber != 3912 {
+		t.Errorf("was expecting draw 3912 but got %+v, %v instead", d, err)
+	}
+
+	if _, err := m.ByNumber(3913); apperror.CodeOf(err) != apperror.DrawNotFound {
+		t.Errorf("was expecting %s but got %v instead", apperror.DrawNotFound, err)
+	}
+
+	if _, err := m.ByDate("2024-10-17"); apperror.CodeOf(err) != apperror.DrawNotFound {
+		t.Errorf("was expecting %s but got %v instead", apperror.DrawNotFound, err)
+	}
+}
+
+func TestMemoryDrawsOrdered(t *testing.T) {
+	later := draw3912()
+	later.DrawNumber = 3913
+	later.DrawDate = "2024-10-17"
+
+	m, err := NewMemory(later, draw3912())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	draws, _ := m.Draws()
+	if len(draws) != 2 || draws[0].DrawNumber != 3912 || draws[1].DrawNumber != 3913 {
+		t.Errorf("was expecting draws 3912 and 3913 but got %+v instead", draws)
+	}
+}
+
+func TestMemoryPutIdempotent(t *testing.T) {
+	m, _ := NewMemory(draw3912())
+
+	if err := m.Put(draw3912()); err != nil {
+		t.Errorf("was not expecting an error putting the same draw: %s", err.Error())
+	}
+
+	withPrizes := draw3912()
+	withPrizes.GroupPrizes = &prizetable.GroupPrizes{Group1: 100000000}
+	if err := m.Put(withPrizes); err != nil {
+		t.Errorf("was not expecting an error adding group prizes: %s", err.Error())
+	}
+
+	d, _ := m.ByNumber(3912)
+	if d.GroupPrizes == nil || d.GroupPrizes.Group1 != 100000000 {
+		t.Errorf("was expecting group prizes to be added but got %+v instead", d.GroupPrizes)
+	}
+
+	if err := m.Put(draw3912()); err != nil {
+		t.Errorf("was not expecting an error putting the draw without group prizes: %s", err.Error())
+	}
+}
+
+func TestMemoryPutConflict(t *testing.T) {
+	m, _ := NewMemory(draw3912())
+
+	different := draw3912()
+	different.AdditionalNumber = 8
+	if err := m.Put(different); apperror.CodeOf(err) != apperror.DrawConflict {
+		t.Errorf("was expecting %s but got %v instead", apperror.DrawConflict, err)
+	}
+
+	sameDate := draw3912()
+	sameDate.DrawNumber = 3913
+	if err := m.Put(sameDate); apperror.CodeOf(err) != apperror.DrawConflict {
+		t.Errorf("was expecting %s but got %v instead", apperror.DrawConflict, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []func(d *Draw){
+		func(d *Draw) { d.DrawNumber = 0 },
+		func(d *Draw) { d.DrawDate = "14/10/2024" },
+		func(d *Draw) { d.WinningNumbers = d.WinningNumbers[:5] },
+		func(d *Draw) { d.WinningNumbers[0] = 50 },
+		func(d *Draw) { d.AdditionalNumber = 3 },
+		func(d *Draw) { d.GroupPrizes = &prizetable.GroupPrizes{Group1: -1} },
+	}
+
+	for i, change := range tests {
+		d := draw3912()
+		change(&d)
+
+		if err := d.Validate(); apperror.CodeOf(err) != apperror.InvalidDraw {
+			t.Errorf("test %d: was expecting %s but got %v instead", i, apperror.InvalidDraw, err)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "draws.json")
+
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening a missing file: %s", err.Error())
+	}
+
+	if err := f.Put(draw3912()); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	reopened, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	d, err := reopened.ByDate("2024-10-14")
+	if err != nil || d.DrawNumber != 3912 || d.AdditionalNumber != 7 {
+		t.Errorf("was expecting draw 3912 but got %+v, %v instead", d, err)
+	}
+}
+
+func TestOpenInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "draws.json")
+	if err := os.WriteFile(path, []byte(`[{"drawNumber": 1, "drawDate": "2024-10-14"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenFile(path); apperror.CodeOf(err) != apperror.InvalidDraw {
+		t.Errorf("was expecting %s but got %v instead", apperror.InvalidDraw, err)
+	}
+}
@@ -0,0 +1,98 @@
+package drawstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is a Store kept in a JSON file holding an array of draws. Every Put
+// that changes the store rewrites the file.
+type File struct {
+	mu     sync.Mutex
+	path   string
+	memory *Memory
+}
+
+// OpenFile reads the draws of a file. A missing file is an empty store, and
+// is created by the first Put.
+func OpenFile(path string) (*File, error) {
+	var draws []Draw
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(b, &draws); err != nil {
+			return nil, fmt.Errorf("invalid draw file %s: %s", path, err.Error())
+		}
+	}
+
+	m, err := NewMemory(draws...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid draw file %s: %w", path, err)
+	}
+
+	return &File{path: path, memory: m}, nil
+}
+
+func (f *File) ByNumber(drawNumber int) (Draw, error) {
+	return f.memory.ByNumber(drawNumber)
+}
+
+func (f *File) ByDate(date string) (Draw, error) {
+	return f.memory.ByDate(date)
+}
+
+func (f *File) Draws() ([]Draw, error) {
+	return f.memory.Draws()
+}
+
+func (f *File) Put(d Draw) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	changed, err := f.memory.put(d)
+	if err != nil || !changed {
+		return err
+	}
+
+	return f.save()
+}
+
+// save writes the draws to a temporary file and renames it over the store,
+// so that the file is never left half written.
+func (f *File) save() error {
+	draws, err := f.memory.Draws()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(draws, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
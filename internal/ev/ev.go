@@ -0,0 +1,75 @@
+package ev
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/probability"
+)
+
+// Analysis is the expected return of a bet. Amounts are in cents.
+//
+// ReturnToPlayer is the expected payout as a share of the cost. The
+// break-even jackpot is the Group 1 share amount at which the expected payout
+// equals the cost, given the other group prizes; it is zero when the bet
+// breaks even without a jackpot.
+type Analysis struct {
+	BetType                string  `json:"betType"`
+	CostCents              int     `json:"costCents"`
+	ExpectedPayoutCents    float64 `json:"expectedPayoutCents"`
+	ExpectedNetCents       float64 `json:"expectedNetCents"`
+	ReturnToPlayer         float64 `json:"returnToPlayer"`
+	Variance               float64 `json:"variance"`
+	StandardDeviationCents float64 `json:"standardDeviationCents"`
+	ExpectedGroup1Shares   float64 `json:"expectedGroup1Shares"`
+	BreakEvenJackpotCents  float64 `json:"breakEvenJackpotCents"`
+}
+
+// Analyze computes the expected return of a bet with the given odds and
+// cost, when each winning share of Group 1 to Group 4 pays the amounts of
+// groupPrizes. Zero group prizes leave only the fixed prizes.
+func Analyze(odds probability.Odds, groupPrizes prizetable.GroupPrizes, costCents int) Analysis {
+	expected := new(big.Rat)
+	expectedSquare := new(big.Rat)
+	group1Shares := new(big.Rat)
+
+	withoutJackpot := groupPrizes
+	withoutJackpot.Group1 = 0
+	expectedWithoutJackpot := new(big.Rat)
+
+	for _, o := range odds.Outcomes {
+		p := o.Probability.Rat()
+		payout := big.NewRat(int64(o.PrizeDetail.PayoutCents(groupPrizes)), 1)
+
+		term := new(big.Rat).Mul(p, payout)
+		expected.Add(expected, term)
+		expectedSquare.Add(expectedSquare, term.Mul(term, payout))
+
+		group1Shares.Add(group1Shares, new(big.Rat).Mul(p, big.NewRat(int64(o.PrizeDetail.Group1), 1)))
+		expectedWithoutJackpot.Add(expectedWithoutJackpot, new(big.Rat).Mul(p, big.NewRat(int64(o.PrizeDetail.PayoutCents(withoutJackpot)), 1)))
+	}
+
+	variance := new(big.Rat).Sub(expectedSquare, new(big.Rat).Mul(expected, expected))
+
+	a := Analysis{
+		BetType:   odds.BetType,
+		CostCents: costCents,
+	}
+	a.ExpectedPayoutCents, _ = expected.Float64()
+	a.ExpectedNetCents = a.ExpectedPayoutCents - float64(costCents)
+	a.Variance, _ = variance.Float64()
+	a.StandardDeviationCents = math.Sqrt(a.Variance)
+	a.ExpectedGroup1Shares, _ = group1Shares.Float64()
+
+	if costCents > 0 {
+		a.ReturnToPlayer = a.ExpectedPayoutCents / float64(costCents)
+	}
+
+	shortfall := new(big.Rat).Sub(big.NewRat(int64(costCents), 1), expectedWithoutJackpot)
+	if shortfall.Sign() > 0 && group1Shares.Sign() > 0 {
+		a.BreakEvenJackpotCents, _ = shortfall.Quo(shortfall, group1Shares).Float64()
+	}
+
+	return a
+}
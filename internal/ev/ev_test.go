@@ -0,0 +1,72 @@
+package ev
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/probability"
+)
+
+func ordinaryOdds(t *testing.T) probability.Odds {
+	table, _ := prizetable.Structure(prizetable.Current)
+
+	odds, err := probability.ForBetType(table, "Ordinary", 49)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	return odds
+}
+
+func closeTo(a float64, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestAnalyzeFixedPrizes(t *testing.T) {
+	a := Analyze(ordinaryOdds(t), prizetable.GroupPrizes{}, 100)
+
+	expected := 615.0/665896*5000 + 205.0/166474*2500 + 4100.0/249711*1000
+	if !closeTo(a.ExpectedPayoutCents, expected) {
+		t.Errorf("was expecting an expected payout of %f but got %f instead", expected, a.ExpectedPayoutCents)
+	}
+
+	if !closeTo(a.ReturnToPlayer, expected/100) || !closeTo(a.ExpectedNetCents, expected-100) {
+		t.Errorf("unexpected return %+v", a)
+	}
+
+	expectedSquare := 615.0/665896*5000*5000 + 205.0/166474*2500*2500 + 4100.0/249711*1000*1000
+	if !closeTo(a.Variance, expectedSquare-expected*expected) {
+		t.Errorf("was expecting a variance of %f but got %f instead", expectedSquare-expected*expected, a.Variance)
+	}
+
+	if !closeTo(a.BreakEvenJackpotCents, (100-expected)*13983816) {
+		t.Errorf("was expecting a break-even jackpot of %f but got %f instead", (100-expected)*13983816, a.BreakEvenJackpotCents)
+	}
+}
+
+func TestAnalyzeGroupPrizes(t *testing.T) {
+	groupPrizes := prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000}
+	a := Analyze(ordinaryOdds(t), groupPrizes, 100)
+	fixed := Analyze(ordinaryOdds(t), prizetable.GroupPrizes{}, 100)
+
+	expected := fixed.ExpectedPayoutCents + 100000000.0/13983816 + 5000000.0/2330636 + 150000*3.0/166474 + 40000*15.0/332948
+	if !closeTo(a.ExpectedPayoutCents, expected) {
+		t.Errorf("was expecting an expected payout of %f but got %f instead", expected, a.ExpectedPayoutCents)
+	}
+
+	if !closeTo(a.ExpectedGroup1Shares, 1.0/13983816) {
+		t.Errorf("unexpected Group 1 shares %f", a.ExpectedGroup1Shares)
+	}
+
+	if a.BreakEvenJackpotCents >= fixed.BreakEvenJackpotCents {
+		t.Errorf("was expecting group prizes to lower the break-even jackpot: %f, %f", a.BreakEvenJackpotCents, fixed.BreakEvenJackpotCents)
+	}
+}
+
+func TestAnalyzeBreaksEven(t *testing.T) {
+	a := Analyze(ordinaryOdds(t), prizetable.GroupPrizes{Group4: 100000000}, 100)
+
+	if a.BreakEvenJackpotCents != 0 || a.ReturnToPlayer <= 1 {
+		t.Errorf("was expecting the bet to break even without a jackpot but got %+v", a)
+	}
+}
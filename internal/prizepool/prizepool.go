@@ -0,0 +1,173 @@
+package prizepool
+
+import (
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+// basisPoints is 100%, in basis points.
+const basisPoints = 10000
+
+// Split is the share of the prize pool that goes to each of Group 1 to
+// Group 4, in basis points: 3800 is 38%.
+type Split struct {
+	Group1 int `json:"group1"`
+	Group2 int `json:"group2"`
+	Group3 int `json:"group3"`
+	Group4 int `json:"group4"`
+}
+
+// Winners counts the winning units, or shares, of each group.
+type Winners struct {
+	Group1 int `json:"group1"`
+	Group2 int `json:"group2"`
+	Group3 int `json:"group3"`
+	Group4 int `json:"group4"`
+	Group5 int `json:"group5"`
+	Group6 int `json:"group6"`
+	Group7 int `json:"group7"`
+}
+
+// Draw describes the prize fund of a draw. The prize fund is
+// PrizeFundBasisPoints of the sales. The fixed Group 5 to Group 7 prizes are
+// paid from it first, and what is left is the prize pool that Split divides
+// among Group 1 to Group 4. SnowballCents is carried over from earlier draws
+// into Group 1.
+type Draw struct {
+	SalesCents           int                    `json:"salesCents"`
+	PrizeFundBasisPoints int                    `json:"prizeFundBasisPoints"`
+	Split                Split                  `json:"split"`
+	Group1MinimumCents   int                    `json:"group1MinimumCents"`
+	FixedGroups          prizetable.FixedGroups `json:"fixedGroups"`
+	SnowballCents        int                    `json:"snowballCents"`
+	Winners              Winners                `json:"winners"`
+}
+
+// Group is the allocation of one of Group 1 to Group 4. PoolCents includes
+// money cascaded from higher groups and, for Group 1, the snowball and any
+// top-up to the minimum. A group without winners passes its pool on as
+// CascadedCents.
+type Group struct {
+	Group         int `json:"group"`
+	PoolCents     int `json:"poolCents"`
+	Winners       int `json:"winners"`
+	ShareCents    int `json:"shareCents"`
+	PaidCents     int `json:"paidCents"`
+	CascadedCents int `json:"cascadedCents"`
+}
+
+// Allocation is how a draw's prize fund is paid out.
+//
+// The Group 1 pool is topped up to the minimum when Group 1 has winners.
+// Without Group 1 winners it snowballs into CarryOverCents. The pools of
+// Group 2 to Group 4 cascade into the next lower group when they have no
+// winners, and into the carry-over when no lower group has any. Shares are
+// rounded down to the cent, and the cents left over are RoundingCents.
+// ShortfallCents is how much the fixed prizes exceed the prize fund by.
+type Allocation struct {
+	PrizeFundCents   int                    `json:"prizeFundCents"`
+	FixedPayoutCents int                    `json:"fixedPayoutCents"`
+	ShortfallCents   int                    `json:"shortfallCents"`
+	PrizePoolCents   int                    `json:"prizePoolCents"`
+	Groups           []Group                `json:"groups"`
+	Group1TopUpCents int                    `json:"group1TopUpCents"`
+	CarryOverCents   int                    `json:"carryOverCents"`
+	RoundingCents    int                    `json:"roundingCents"`
+	GroupPrizes      prizetable.GroupPrizes `json:"groupPrizes"`
+}
+
+// Allocate divides the prize fund of a draw among the groups.
+func Allocate(d Draw) (Allocation, error) {
+	if err := d.validate(); err != nil {
+		return Allocation{}, err
+	}
+
+	var a Allocation
+	w := d.Winners
+	g := d.FixedGroups
+
+	a.PrizeFundCents = d.SalesCents * d.PrizeFundBasisPoints / basisPoints
+	a.FixedPayoutCents = w.Group5*g.Group5 + w.Group6*g.Group6 + w.Group7*g.Group7
+
+	a.PrizePoolCents = a.PrizeFundCents - a.FixedPayoutCents
+	if a.PrizePoolCents < 0 {
+		a.ShortfallCents = -a.PrizePoolCents
+		a.PrizePoolCents = 0
+	}
+
+	split := []int{d.Split.Group1, d.Split.Group2, d.Split.Group3, d.Split.Group4}
+	winners := []int{w.Group1, w.Group2, w.Group3, w.Group4}
+	shares := make([]int, 4)
+
+	// Cents lost dividing the pool by the split join the rounding.
+	allocated := 0
+	cascaded := 0
+	for i := range split {
+		pool := a.PrizePoolCents * split[i] / basisPoints
+		allocated += pool
+
+		group := Group{Group: i + 1, PoolCents: pool, Winners: winners[i]}
+		if i == 0 {
+			group.PoolCents += d.SnowballCents
+			if winners[i] > 0 && group.PoolCents < d.Group1MinimumCents {
+				a.Group1TopUpCents = d.Group1MinimumCents - group.PoolCents
+				group.PoolCents = d.Group1MinimumCents
+			}
+		} else {
+			group.PoolCents += cascaded
+			cascaded = 0
+		}
+
+		if winners[i] == 0 {
+			group.CascadedCents = group.PoolCents
+			if i == 0 {
+				a.CarryOverCents += group.PoolCents
+			} else {
+				cascaded = group.PoolCents
+			}
+		} else {
+			group.ShareCents = group.PoolCents / winners[i]
+			group.PaidCents = group.ShareCents * winners[i]
+			a.RoundingCents += group.PoolCents - group.PaidCents
+		}
+
+		shares[i] = group.ShareCents
+		a.Groups = append(a.Groups, group)
+	}
+
+	a.CarryOverCents += cascaded
+	a.RoundingCents += a.PrizePoolCents*sum(split)/basisPoints - allocated
+	a.CarryOverCents += a.PrizePoolCents - a.PrizePoolCents*sum(split)/basisPoints
+
+	a.GroupPrizes = prizetable.GroupPrizes{Group1: shares[0], Group2: shares[1], Group3: shares[2], Group4: shares[3]}
+	return a, nil
+}
+
+func (d Draw) validate() error {
+	w := d.Winners
+	for _, n := range []int{d.SalesCents, d.PrizeFundBasisPoints, d.Split.Group1, d.Split.Group2, d.Split.Group3, d.Split.Group4,
+		d.Group1MinimumCents, d.FixedGroups.Group5, d.FixedGroups.Group6, d.FixedGroups.Group7, d.SnowballCents,
+		w.Group1, w.Group2, w.Group3, w.Group4, w.Group5, w.Group6, w.Group7} {
+		if n < 0 {
+			return apperror.New(apperror.InvalidRequest, "prize pool amounts and counts should not be negative")
+		}
+	}
+
+	if d.PrizeFundBasisPoints > basisPoints {
+		return apperror.Errorf(apperror.InvalidRequest, "prize fund should be at most 100%% of sales, got %d basis points", d.PrizeFundBasisPoints)
+	}
+
+	s := d.Split
+	if total := sum([]int{s.Group1, s.Group2, s.Group3, s.Group4}); total > basisPoints {
+		return apperror.Errorf(apperror.InvalidRequest, "group split should add up to at most 100%%, got %d basis points", total)
+	}
+	return nil
+}
+
+func sum(numbers []int) int {
+	total := 0
+	for _, n := range numbers {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,154 @@
+package prizepool
+
+import (
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+func draw() Draw {
+	return Draw{
+		SalesCents:           1000000000,
+		PrizeFundBasisPoints: 5400,
+		Split:                Split{Group1: 3800, Group2: 800, Group3: 550, Group4: 300},
+		Group1MinimumCents:   100000000,
+		FixedGroups:          prizetable.FixedGroups{Group5: 5000, Group6: 2500, Group7: 1000},
+		Winners:              Winners{Group1: 1, Group2: 3, Group3: 70, Group4: 200, Group5: 5000, Group6: 8000, Group7: 100000},
+	}
+}
+
+// balanced checks that every cent of the prize fund, snowball and top-up is
+// paid, carried over or lost to rounding.
+func balanced(t *testing.T, d Draw, a Allocation) {
+	t.Helper()
+
+	in := a.PrizeFundCents + d.SnowballCents + a.Group1TopUpCents + a.ShortfallCents
+	out := a.FixedPayoutCents + a.CarryOverCents + a.RoundingCents
+	for _, g := range a.Groups {
+		out += g.PaidCents
+	}
+
+	if in != out {
+		t.Errorf("was expecting %d cents to be allocated but got %d instead: %+v", in, out, a)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	d := draw()
+	a, err := Allocate(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	// Prize fund $5,400,000, fixed prizes $1,450,000, pool $3,950,000.
+	if a.PrizeFundCents != 540000000 || a.FixedPayoutCents != 145000000 || a.PrizePoolCents != 395000000 {
+		t.Errorf("unexpected prize fund %+v", a)
+	}
+
+	expected := prizetable.GroupPrizes{Group1: 150100000, Group2: 10533333, Group3: 310357, Group4: 59250}
+	if a.GroupPrizes != expected {
+		t.Errorf("was expecting %+v but got %+v instead", expected, a.GroupPrizes)
+	}
+
+	// Group 2 and Group 3 shares leave 1 and 10 cents, and the remaining
+	// 45.5% of the pool is carried over.
+	if a.RoundingCents != 11 || a.CarryOverCents != 179725000 {
+		t.Errorf("unexpected rounding and carry-over %+v", a)
+	}
+
+	balanced(t, d, a)
+}
+
+func TestAllocateSnowball(t *testing.T) {
+	d := draw()
+	d.Winners.Group1 = 0
+	d.SnowballCents = 50000000
+
+	a, err := Allocate(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if a.Groups[0].CascadedCents != 200100000 || a.GroupPrizes.Group1 != 0 {
+		t.Errorf("was expecting the Group 1 pool to snowball but got %+v", a.Groups[0])
+	}
+
+	if a.CarryOverCents != 179725000+200100000 {
+		t.Errorf("unexpected carry-over %d", a.CarryOverCents)
+	}
+
+	balanced(t, d, a)
+}
+
+func TestAllocateCascade(t *testing.T) {
+	d := draw()
+	d.Winners.Group2 = 0
+	d.Winners.Group4 = 0
+
+	a, err := Allocate(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	// Group 2 cascades into Group 3, and Group 4 has nothing below it.
+	if a.Groups[1].CascadedCents != 31600000 || a.Groups[2].PoolCents != 31600000+21725000 {
+		t.Errorf("was expecting Group 2 to cascade into Group 3 but got %+v", a.Groups)
+	}
+
+	if a.CarryOverCents != 179725000+11850000 {
+		t.Errorf("was expecting Group 4 to be carried over but got %d", a.CarryOverCents)
+	}
+
+	balanced(t, d, a)
+}
+
+func TestAllocateGroup1Minimum(t *testing.T) {
+	d := draw()
+	d.SalesCents = 300000000
+
+	a, err := Allocate(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if a.GroupPrizes.Group1 != 100000000 || a.Group1TopUpCents == 0 {
+		t.Errorf("was expecting Group 1 to be topped up to the minimum but got %+v", a)
+	}
+
+	balanced(t, d, a)
+}
+
+func TestAllocateShortfall(t *testing.T) {
+	d := draw()
+	d.SalesCents = 50000000
+
+	a, err := Allocate(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if a.ShortfallCents != 145000000-27000000 || a.PrizePoolCents != 0 {
+		t.Errorf("was expecting a shortfall but got %+v", a)
+	}
+
+	balanced(t, d, a)
+}
+
+func TestAllocateInvalid(t *testing.T) {
+	tests := []func(d *Draw){
+		func(d *Draw) { d.SalesCents = -1 },
+		func(d *Draw) { d.Winners.Group3 = -1 },
+		func(d *Draw) { d.PrizeFundBasisPoints = 10001 },
+		func(d *Draw) { d.Split.Group1 = 9500 },
+	}
+
+	for i, change := range tests {
+		d := draw()
+		change(&d)
+
+		if _, err := Allocate(d); apperror.CodeOf(err) != apperror.InvalidRequest {
+			t.Errorf("test %d: was expecting %s but got %v instead", i, apperror.InvalidRequest, err)
+		}
+	}
+}
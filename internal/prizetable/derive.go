@@ -0,0 +1,109 @@
+package prizetable
+
+import "fmt"
+
+// Add returns the sum of both prizes.
+func (p Prize) Add(o Prize) Prize {
+	return Prize{
+		Group1:     p.Group1 + o.Group1,
+		Group2:     p.Group2 + o.Group2,
+		Group3:     p.Group3 + o.Group3,
+		Group4:     p.Group4 + o.Group4,
+		Group5:     p.Group5 + o.Group5,
+		Group6:     p.Group6 + o.Group6,
+		Group7:     p.Group7 + o.Group7,
+		FixedCents: p.FixedCents + o.FixedCents,
+		Won:        p.Won || o.Won,
+	}
+}
+
+// Times returns the prize won n times over.
+func (p Prize) Times(n int) Prize {
+	if n <= 0 {
+		return Prize{}
+	}
+
+	return Prize{
+		Group1:     p.Group1 * n,
+		Group2:     p.Group2 * n,
+		Group3:     p.Group3 * n,
+		Group4:     p.Group4 * n,
+		Group5:     p.Group5 * n,
+		Group6:     p.Group6 * n,
+		Group7:     p.Group7 * n,
+		FixedCents: p.FixedCents * n,
+		Won:        p.Won,
+	}
+}
+
+// DerivePrize computes the prize of a bet with betSize numbers from the
+// Ordinary prizes of the C(betSize, 6) ordinary combinations it contains.
+func (t *Table) DerivePrize(betSize int, numbersMatched int, hasAdditionalNumber bool) Prize {
+	additional := 0
+	if hasAdditionalNumber {
+		additional = 1
+	}
+	others := betSize - numbersMatched - additional
+
+	var p Prize
+	for w := 0; w <= 6; w++ {
+		for a := 0; a <= additional; a++ {
+			count := binomial(numbersMatched, w) * binomial(additional, a) * binomial(others, 6-w-a)
+			p = p.Add(t.GetPrizeDetail("Ordinary", w, a == 1).Times(count))
+		}
+	}
+
+	return p
+}
+
+// DeriveRollPrize computes the prize of a System Roll bet, whose 5 chosen
+// numbers are played with every other number up to maxNumber, from the
+// Ordinary prizes of those combinations. numbersMatched and
+// hasAdditionalNumber count the chosen numbers only.
+func (t *Table) DeriveRollPrize(maxNumber int, numbersMatched int, hasAdditionalNumber bool) Prize {
+	additional := 0
+	if hasAdditionalNumber {
+		additional = 1
+	}
+	winningLeft := 6 - numbersMatched
+	additionalLeft := 1 - additional
+	othersLeft := maxNumber - 5 - winningLeft - additionalLeft
+
+	p := t.GetPrizeDetail("Ordinary", numbersMatched+1, hasAdditionalNumber).Times(winningLeft)
+	p = p.Add(t.GetPrizeDetail("Ordinary", numbersMatched, true).Times(additionalLeft))
+	p = p.Add(t.GetPrizeDetail("Ordinary", numbersMatched, hasAdditionalNumber).Times(othersLeft))
+
+	return p
+}
+
+// Verify checks that every System bet prize in the table matches the prize
+// derived from the Ordinary prizes.
+func (t *Table) Verify() error {
+	for size := 7; size <= 12; size++ {
+		betType := fmt.Sprintf("System %d", size)
+
+		for _, hasAdditionalNumber := range []bool{false, true} {
+			for numbersMatched := 0; numbersMatched <= 6; numbersMatched++ {
+				expected := t.DerivePrize(size, numbersMatched, hasAdditionalNumber)
+				actual := t.GetPrizeDetail(betType, numbersMatched, hasAdditionalNumber)
+				if actual != expected {
+					return fmt.Errorf("%s prize for %d numbers matched, additional number %t should be %s (%+v) but is %s (%+v)", betType, numbersMatched, hasAdditionalNumber, expected, expected, actual, actual)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func binomial(n int, k int) int {
+	if k < 0 || n < 0 || k > n {
+		return 0
+	}
+
+	r := 1
+	for i := 0; i < k; i++ {
+		r = r * (n - i) / (i + 1)
+	}
+	return r
+}
@@ -0,0 +1,109 @@
+package prizetable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prize is the machine-readable form of a prize. Group1 to Group4 hold the
+// number of shares won in each pari-mutuel group and Group5 to Group7 the
+// number of fixed prizes won in each fixed group. FixedCents holds the total
+// fixed cash amount won, in cents, including the Group 5 to Group 7 prizes.
+type Prize struct {
+	Group1     int  `json:"group1"`
+	Group2     int  `json:"group2"`
+	Group3     int  `json:"group3"`
+	Group4     int  `json:"group4"`
+	Group5     int  `json:"group5"`
+	Group6     int  `json:"group6"`
+	Group7     int  `json:"group7"`
+	FixedCents int  `json:"fixedCents"`
+	Won        bool `json:"won"`
+}
+
+func (p Prize) hasWinnings() bool {
+	return p.Group1 > 0 || p.Group2 > 0 || p.Group3 > 0 || p.Group4 > 0 ||
+		p.Group5 > 0 || p.Group6 > 0 || p.Group7 > 0 || p.FixedCents > 0
+}
+
+// String formats the prize the way the Singapore Pools prize tables do,
+// e.g. "Group 1 + 3 + Group 6 ×3 + Group 7". Each pari-mutuel group is listed
+// once regardless of the number of shares won in it, and each fixed group
+// with the number of prizes won in it. Fixed prizes outside the fixed groups,
+// as in the legacy structure, are added up into a single amount.
+func (p Prize) String() string {
+	var groups []string
+	for i, shares := range []int{p.Group1, p.Group2, p.Group3, p.Group4} {
+		if shares > 0 {
+			groups = append(groups, strconv.Itoa(i+1))
+		}
+	}
+
+	var parts []string
+	if len(groups) > 0 {
+		parts = append(parts, "Group "+strings.Join(groups, " + "))
+	}
+
+	fixedGroups := false
+	for i, prizes := range []int{p.Group5, p.Group6, p.Group7} {
+		switch {
+		case prizes == 1:
+			parts = append(parts, fmt.Sprintf("Group %d", i+5))
+		case prizes > 1:
+			parts = append(parts, fmt.Sprintf("Group %d ×%d", i+5, prizes))
+		}
+		fixedGroups = fixedGroups || prizes > 0
+	}
+
+	if p.FixedCents > 0 && !fixedGroups {
+		parts = append(parts, formatCents(p.FixedCents))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+func formatCents(cents int) string {
+	dollars := strconv.Itoa(cents / 100)
+
+	var b strings.Builder
+	for i, d := range dollars {
+		if i > 0 && (len(dollars)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(d)
+	}
+
+	if c := cents % 100; c > 0 {
+		return fmt.Sprintf("$%s.%02d", b.String(), c)
+	}
+
+	return "$" + b.String()
+}
+
+// FixedGroups holds the amount, in cents, of each fixed Group 5 to Group 7
+// prize.
+type FixedGroups struct {
+	Group5 int `json:"group5"`
+	Group6 int `json:"group6"`
+	Group7 int `json:"group7"`
+}
+
+// GroupPrizes holds the amount, in cents, paid for each share of the Group 1
+// to Group 4 prizes of a draw.
+type GroupPrizes struct {
+	Group1 int `json:"group1"`
+	Group2 int `json:"group2"`
+	Group3 int `json:"group3"`
+	Group4 int `json:"group4"`
+}
+
+func (g GroupPrizes) IsValid() bool {
+	return g.Group1 >= 0 && g.Group2 >= 0 && g.Group3 >= 0 && g.Group4 >= 0
+}
+
+// PayoutCents returns the total amount won, in cents, given the share amounts
+// of the draw.
+func (p Prize) PayoutCents(g GroupPrizes) int {
+	return p.Group1*g.Group1 + p.Group2*g.Group2 + p.Group3*g.Group3 + p.Group4*g.Group4 + p.FixedCents
+}
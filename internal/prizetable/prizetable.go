@@ -1,216 +1,243 @@
 package prizetable
 
-func GetPrize(betType string, numbersMatched int, hasAdditionalNumber bool) string {
-	switch betType {
-	case "Ordinary":
-		return getOrdinaryPrize(numbersMatched, hasAdditionalNumber)
-	case "System 7":
-		return getSystemSevenPrize(numbersMatched, hasAdditionalNumber)
-	case "System 8":
-		return getSystemEightPrize(numbersMatched, hasAdditionalNumber)
-	case "System 9":
-		return getSystemNinePrize(numbersMatched, hasAdditionalNumber)
-	case "System 10":
-		return getSystemTenPrize(numbersMatched, hasAdditionalNumber)
-	case "System 11":
-		return getSystemElevenPrize(numbersMatched, hasAdditionalNumber)
-	case "System 12":
-		return getSystemTwelvePrize(numbersMatched, hasAdditionalNumber)
-	}
-	return ""
-}
-
-func getOrdinaryPrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$10"
-		case 4:
-			return "$50"
-		case 5:
-			return "Group 3"
-		case 6:
-			return "Group 1"
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NoPrize describes the prize of a bet that won nothing.
+const NoPrize = "no prize"
+
+// Current is the prize structure in force today, with fixed Group 5 to
+// Group 7 prizes. Legacy is the earlier structure with Group 1 to Group 4
+// prizes and unnamed fixed cash prizes.
+const (
+	Current = "current"
+	Legacy  = "legacy"
+)
+
+//go:embed prizetable.json
+var embeddedTable []byte
+
+//go:embed legacy.json
+var embeddedLegacyTable []byte
+
+var betSizes = map[string]int{
+	"Ordinary":  6,
+	"System 7":  7,
+	"System 8":  8,
+	"System 9":  9,
+	"System 10": 10,
+	"System 11": 11,
+	"System 12": 12,
+}
 
-		}
+var tables = map[string]*Table{
+	Current: mustLoad(embeddedTable),
+	Legacy:  mustLoad(embeddedLegacyTable),
+}
+
+var table = tables[Current]
+
+type match struct {
+	numbersMatched      int
+	hasAdditionalNumber bool
+}
+
+// Table holds the prizes of every bet type, keyed by the numbers matched and
+// whether the additional number was matched.
+type Table struct {
+	fixedGroups FixedGroups
+	prizes      map[string]map[match]Prize
+}
+
+type definition struct {
+	FixedGroups FixedGroups        `json:"fixedGroups"`
+	Prizes      map[string][]entry `json:"prizes"`
+}
+
+type entry struct {
+	NumbersMatched      int   `json:"numbersMatched"`
+	HasAdditionalNumber bool  `json:"hasAdditionalNumber"`
+	Prize               Prize `json:"prize"`
+}
+
+// Load reads a prize table definition. The definition holds the amounts of
+// the fixed groups, if any, and maps every bet type to its winning entries;
+// matches without an entry win nothing.
+func Load(r io.Reader) (*Table, error) {
+	var d definition
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&d); err != nil {
+		return nil, fmt.Errorf("invalid prize table: %s", err.Error())
 	}
 
-	switch numbersMatched {
-	case 3:
-		return "$25"
-	case 4:
-		return "Group 4"
-	case 5:
-		return "Group 2"
+	g := d.FixedGroups
+	if g.Group5 < 0 || g.Group6 < 0 || g.Group7 < 0 {
+		return nil, fmt.Errorf("invalid prize table: fixed group amounts should not be negative: %+v", g)
 	}
 
-	return "unknown"
-}
+	t := &Table{
+		fixedGroups: g,
+		prizes:      make(map[string]map[match]Prize, len(d.Prizes)),
+	}
+
+	for betType, entries := range d.Prizes {
+		size, ok := betSizes[betType]
+		if !ok {
+			return nil, fmt.Errorf("invalid prize table: unknown bet type %s", betType)
+		}
 
-func getSystemSevenPrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$40"
-		case 4:
-			return "$190"
-		case 5:
-			return "Group 3 + $250"
-		case 6:
-			return "Group 1 + 3"
+		prizes := make(map[match]Prize, len(entries))
+		for _, e := range entries {
+			if err := e.validate(size, g); err != nil {
+				return nil, fmt.Errorf("invalid prize table: %s: %s", betType, err.Error())
+			}
+
+			m := match{e.NumbersMatched, e.HasAdditionalNumber}
+			if _, found := prizes[m]; found {
+				return nil, fmt.Errorf("invalid prize table: %s: duplicate entry for %d numbers matched, additional number %t", betType, e.NumbersMatched, e.HasAdditionalNumber)
+			}
+
+			p := e.Prize
+			p.FixedCents += p.Group5*g.Group5 + p.Group6*g.Group6 + p.Group7*g.Group7
+			p.Won = p.hasWinnings()
+			prizes[m] = p
 		}
+
+		t.prizes[betType] = prizes
 	}
 
-	switch numbersMatched {
-	case 3:
-		return "$85"
-	case 4:
-		return "Group 4 + $150"
-	case 5:
-		return "Group 2 + 3 + 4"
-	case 6:
-		return "Group 1 + 2"
+	for betType := range betSizes {
+		if _, ok := t.prizes[betType]; !ok {
+			return nil, fmt.Errorf("invalid prize table: missing bet type %s", betType)
+		}
 	}
 
-	return "unknown"
+	return t, nil
 }
 
-func getSystemEightPrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$100"
-		case 4:
-			return "$460"
-		case 5:
-			return "Group 3 + $850"
-		case 6:
-			return "Group 1 + 3 + $750"
-		}
+// LoadFile reads a prize table definition from a file.
+func LoadFile(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	switch numbersMatched {
-	case 3:
-		return "$190"
-	case 4:
-		return "Group 4 + $490"
-	case 5:
-		return "Group 2 + 3 + 4 + $500"
-	case 6:
-		return "Group 1 + 2 + 3 + 4"
-	}
+	return Load(f)
+}
 
-	return "unknown"
+// Use replaces the current prize table, used by GetPrize and
+// GetPrizeDetail. It is meant to be called once at startup.
+func Use(t *Table) {
+	tables[Current] = t
+	table = t
 }
 
-func getSystemNinePrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$200"
-		case 4:
-			return "$900"
-		case 5:
-			return "Group 3 + $1,900"
-		case 6:
-			return "Group 1 + 3 + $2,450"
-		}
+// Structure returns the prize table of the named prize structure.
+func Structure(name string) (*Table, bool) {
+	t, ok := tables[name]
+	return t, ok
+}
+
+func mustLoad(b []byte) *Table {
+	t, err := Load(bytes.NewReader(b))
+	if err != nil {
+		panic(err)
 	}
+	return t
+}
 
-	switch numbersMatched {
-	case 3:
-		return "$350"
-	case 4:
-		return "Group 4 + $1,060"
-	case 5:
-		return "Group 2 + 3 + 4 + $1,600"
-	case 6:
-		return "Group 1 + 2 + 3 + 4 + $1,250"
+func (e entry) validate(size int, g FixedGroups) error {
+	if e.NumbersMatched < 0 || e.NumbersMatched > 6 {
+		return fmt.Errorf("numbers matched should be between 0 and 6: %d", e.NumbersMatched)
 	}
 
-	return "unknown"
-}
+	matched := e.NumbersMatched
+	if e.HasAdditionalNumber {
+		matched += 1
+	}
 
-func getSystemTenPrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$350"
-		case 4:
-			return "$1,550"
-		case 5:
-			return "Group 3 + $3,500"
-		case 6:
-			return "Group 1 + 3 + $5,300"
+	if matched > size {
+		return fmt.Errorf("cannot match %d numbers with additional number %t", e.NumbersMatched, e.HasAdditionalNumber)
+	}
+
+	p := e.Prize
+	if p.Group1 < 0 || p.Group2 < 0 || p.Group3 < 0 || p.Group4 < 0 ||
+		p.Group5 < 0 || p.Group6 < 0 || p.Group7 < 0 || p.FixedCents < 0 {
+		return fmt.Errorf("prize amounts should not be negative: %+v", p)
+	}
+
+	for i, fixed := range []struct{ shares, cents int }{{p.Group5, g.Group5}, {p.Group6, g.Group6}, {p.Group7, g.Group7}} {
+		if fixed.shares > 0 && fixed.cents == 0 {
+			return fmt.Errorf("group %d has no fixed amount", i+5)
 		}
 	}
 
-	switch numbersMatched {
-	case 3:
-		return "$575"
-	case 4:
-		return "Group 4 + $1,900"
-	case 5:
-		return "Group 2 + 3 + 4 + $3,400"
-	case 6:
-		return "Group 1 + 2 + 3 + 4 + $3,950"
+	if !p.hasWinnings() {
+		return fmt.Errorf("entry for %d numbers matched, additional number %t has no prize", e.NumbersMatched, e.HasAdditionalNumber)
 	}
 
-	return "unknown"
+	return nil
 }
 
-func getSystemElevenPrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$560"
-		case 4:
-			return "$2,450"
-		case 5:
-			return "Group 3 + $5,750"
-		case 6:
-			return "Group 1 + 3 + $9,500"
-		}
+// FixedGroups returns the amounts of the fixed Group 5 to Group 7 prizes. They
+// are all zero in structures without fixed groups.
+func (t *Table) FixedGroups() FixedGroups {
+	return t.fixedGroups
+}
+
+func GetPrize(betType string, numbersMatched int, hasAdditionalNumber bool) string {
+	return table.GetPrize(betType, numbersMatched, hasAdditionalNumber)
+}
+
+// GetPrizeDetail returns the structured prize for the given bet type and
+// match. The zero Prize is returned for unknown bet types.
+func GetPrizeDetail(betType string, numbersMatched int, hasAdditionalNumber bool) Prize {
+	return table.GetPrizeDetail(betType, numbersMatched, hasAdditionalNumber)
+}
+
+func (t *Table) GetPrize(betType string, numbersMatched int, hasAdditionalNumber bool) string {
+	p, ok := t.getPrize(betType, numbersMatched, hasAdditionalNumber)
+	if !ok {
+		return ""
 	}
 
-	switch numbersMatched {
-	case 3:
-		return "$875"
-	case 4:
-		return "Group 4 + $3,050"
-	case 5:
-		return "Group 2 + 3 + 4 + $6,000"
-	case 6:
-		return "Group 1 + 2 + 3 + 4 + $8,300"
+	if !p.Won {
+		return NoPrize
 	}
 
-	return "unknown"
+	return p.String()
 }
 
-func getSystemTwelvePrize(numbersMatched int, hasAdditionalNumber bool) string {
-	if !hasAdditionalNumber {
-		switch numbersMatched {
-		case 3:
-			return "$840"
-		case 4:
-			return "$3,640"
-		case 5:
-			return "Group 3 + $8,750"
-		case 6:
-			return "Group 1 + 3 + $15,250"
-		}
+func (t *Table) GetPrizeDetail(betType string, numbersMatched int, hasAdditionalNumber bool) Prize {
+	p, _ := t.getPrize(betType, numbersMatched, hasAdditionalNumber)
+	return p
+}
+
+// GetRollPrize is GetPrize for System Roll bets in a game whose numbers run
+// up to maxNumber.
+func (t *Table) GetRollPrize(maxNumber int, numbersMatched int, hasAdditionalNumber bool) string {
+	p := t.DeriveRollPrize(maxNumber, numbersMatched, hasAdditionalNumber)
+	if !p.Won {
+		return NoPrize
 	}
 
-	switch numbersMatched {
-	case 3:
-		return "$1,260"
-	case 4:
-		return "Group 4 + $4,550"
-	case 5:
-		return "Group 2 + 3 + 4 + $9,500"
-	case 6:
-		return "Group 1 + 2 + 3 + 4 + $14,500"
+	return p.String()
+}
+
+func (t *Table) getPrize(betType string, numbersMatched int, hasAdditionalNumber bool) (Prize, bool) {
+	prizes, ok := t.prizes[betType]
+	if !ok {
+		return Prize{}, false
 	}
 
-	return "unknown"
+	return prizes[match{numbersMatched, hasAdditionalNumber}], true
 }
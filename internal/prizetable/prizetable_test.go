@@ -1,12 +1,15 @@
 package prizetable
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
-func TestGetPrizeOrdinaryTenDollars(t *testing.T) {
+func TestGetPrizeOrdinaryGroupSeven(t *testing.T) {
 
 	p := GetPrize("Ordinary", 3, false)
 
-	expectedPrize := "$10"
+	expectedPrize := "Group 7"
 
 	if p != expectedPrize {
 		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
@@ -14,11 +17,11 @@ func TestGetPrizeOrdinaryTenDollars(t *testing.T) {
 
 }
 
-func TestGetPrizeOrdinaryFiftyDollars(t *testing.T) {
+func TestGetPrizeOrdinaryGroupFive(t *testing.T) {
 
 	p := GetPrize("Ordinary", 4, false)
 
-	expectedPrize := "$50"
+	expectedPrize := "Group 5"
 
 	if p != expectedPrize {
 		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
@@ -50,11 +53,11 @@ func TestGetPrizeOrdinaryGroupOne(t *testing.T) {
 
 }
 
-func TestGetPrizeOrdinaryTwentyFiveDollars(t *testing.T) {
+func TestGetPrizeOrdinaryGroupSix(t *testing.T) {
 
 	p := GetPrize("Ordinary", 3, true)
 
-	expectedPrize := "$25"
+	expectedPrize := "Group 6"
 
 	if p != expectedPrize {
 		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
@@ -62,6 +65,23 @@ func TestGetPrizeOrdinaryTwentyFiveDollars(t *testing.T) {
 
 }
 
+func TestGetPrizeSystemSevenFixedGroups(t *testing.T) {
+
+	p := GetPrize("System 7", 3, true)
+
+	expectedPrize := "Group 6 ×3 + Group 7"
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
+	}
+
+	expectedCents := 3*2500 + 1000
+	if d := GetPrizeDetail("System 7", 3, true); d.FixedCents != expectedCents {
+		t.Errorf("expecting fixed cents: %d, got %d instead", expectedCents, d.FixedCents)
+	}
+
+}
+
 func TestGetPrizeOrdinaryGroupFour(t *testing.T) {
 
 	p := GetPrize("Ordinary", 4, true)
@@ -85,3 +105,220 @@ func TestGetPrizeOrdinaryGroupTwo(t *testing.T) {
 	}
 
 }
+
+func TestGetPrizeDetailOrdinaryGroupOne(t *testing.T) {
+
+	p := GetPrizeDetail("Ordinary", 6, false)
+
+	expectedPrize := Prize{Group1: 1, Won: true}
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %+v, got %+v instead", expectedPrize, p)
+	}
+
+}
+
+func TestGetPrizeDetailSystemNineGroupFour(t *testing.T) {
+
+	p := GetPrizeDetail("System 9", 4, true)
+
+	expectedPrize := Prize{Group4: 4, Group5: 6, Group6: 24, Group7: 16, FixedCents: 106000, Won: true}
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %+v, got %+v instead", expectedPrize, p)
+	}
+
+}
+
+func TestGetPrizeDetailNoPrize(t *testing.T) {
+
+	p := GetPrizeDetail("Ordinary", 2, true)
+
+	if p.Won {
+		t.Errorf("expecting prize to not be won, got %+v instead", p)
+	}
+
+}
+
+func TestPrizeString(t *testing.T) {
+
+	p := Prize{Group1: 1, Group2: 6, Group3: 30, Group4: 75, FixedCents: 1450000}
+
+	expectedString := "Group 1 + 2 + 3 + 4 + $14,500"
+
+	if p.String() != expectedString {
+		t.Errorf("expecting string: %s, got %s instead", expectedString, p.String())
+	}
+
+}
+
+func TestLoadTable(t *testing.T) {
+	definition := `{"prizes": {
+		"Ordinary": [{"numbersMatched": 3, "hasAdditionalNumber": false, "prize": {"fixedCents": 2000}}],
+		"System 7": [], "System 8": [], "System 9": [], "System 10": [], "System 11": [], "System 12": []
+	}}`
+
+	table, err := Load(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("unexpected error loading table: %v", err)
+	}
+
+	expectedPrize := "$20"
+	p := table.GetPrize("Ordinary", 3, false)
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
+	}
+
+}
+
+func TestLoadTableMissingBetType(t *testing.T) {
+	definition := `{"prizes": {"Ordinary": []}}`
+
+	_, err := Load(strings.NewReader(definition))
+	if err == nil {
+		t.Errorf("expecting error loading table with missing bet types")
+	}
+
+}
+
+func TestLoadTableImpossibleMatch(t *testing.T) {
+	definition := `{"prizes": {
+		"Ordinary": [{"numbersMatched": 6, "hasAdditionalNumber": true, "prize": {"group1": 1}}],
+		"System 7": [], "System 8": [], "System 9": [], "System 10": [], "System 11": [], "System 12": []
+	}}`
+
+	_, err := Load(strings.NewReader(definition))
+
+	expectedError := "invalid prize table: Ordinary: cannot match 6 numbers with additional number true"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+
+}
+
+func TestVerifyEmbeddedTable(t *testing.T) {
+
+	if err := table.Verify(); err != nil {
+		t.Errorf("expecting embedded table to match derived prizes, got %v instead", err)
+	}
+
+}
+
+func TestVerifyEmbeddedLegacyTable(t *testing.T) {
+
+	if err := tables[Legacy].Verify(); err != nil {
+		t.Errorf("expecting embedded legacy table to match derived prizes, got %v instead", err)
+	}
+
+}
+
+func TestVerifyWrongFixedAmount(t *testing.T) {
+	definition := strings.Replace(string(embeddedLegacyTable), `{"group4": 4, "fixedCents": 106000}`, `{"group4": 4, "fixedCents": 160000}`, 1)
+
+	wrongTable, err := Load(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("unexpected error loading table: %v", err)
+	}
+
+	if err := wrongTable.Verify(); err == nil {
+		t.Errorf("expecting error verifying table with wrong System 9 prize")
+	}
+
+}
+
+func TestPrizePayoutCents(t *testing.T) {
+
+	p := GetPrizeDetail("System 9", 4, true)
+	g := GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000}
+
+	expectedPayout := 4*40000 + 106000
+	actualPayout := p.PayoutCents(g)
+
+	if actualPayout != expectedPayout {
+		t.Errorf("expecting payout: %d, got %d instead", expectedPayout, actualPayout)
+	}
+
+}
+
+func TestVerifyWrongFixedGroupCount(t *testing.T) {
+	definition := strings.Replace(string(embeddedTable), `{"group4": 4, "group5": 6, "group6": 24, "group7": 16}`, `{"group4": 4, "group5": 6, "group6": 24, "group7": 61}`, 1)
+
+	wrongTable, err := Load(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("unexpected error loading table: %v", err)
+	}
+
+	if err := wrongTable.Verify(); err == nil {
+		t.Errorf("expecting error verifying table with wrong System 9 prize")
+	}
+
+}
+
+func TestLoadTableMissingFixedGroupAmount(t *testing.T) {
+	definition := `{"fixedGroups": {"group5": 5000}, "prizes": {
+		"Ordinary": [{"numbersMatched": 3, "hasAdditionalNumber": false, "prize": {"group7": 1}}],
+		"System 7": [], "System 8": [], "System 9": [], "System 10": [], "System 11": [], "System 12": []
+	}}`
+
+	_, err := Load(strings.NewReader(definition))
+
+	expectedError := "invalid prize table: Ordinary: group 7 has no fixed amount"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+
+}
+
+func TestGetPrizeDetailLegacyStructure(t *testing.T) {
+
+	legacy, ok := Structure(Legacy)
+	if !ok {
+		t.Fatalf("expecting legacy structure to exist")
+	}
+
+	p := legacy.GetPrizeDetail("Ordinary", 3, true)
+
+	expectedPrize := Prize{FixedCents: 2500, Won: true}
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %+v, got %+v instead", expectedPrize, p)
+	}
+
+}
+
+func TestGetPrizeDetailCurrentStructure(t *testing.T) {
+
+	p := GetPrizeDetail("Ordinary", 3, true)
+
+	expectedPrize := Prize{Group6: 1, FixedCents: 2500, Won: true}
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %+v, got %+v instead", expectedPrize, p)
+	}
+
+}
+
+func TestDeriveRollPrize(t *testing.T) {
+
+	p := table.DeriveRollPrize(49, 4, true)
+
+	expectedPrize := Prize{Group2: 2, Group4: 42, FixedCents: 0, Won: true}
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %+v, got %+v instead", expectedPrize, p)
+	}
+
+}
+
+func TestGetPrizeOrdinaryNoPrize(t *testing.T) {
+
+	p := GetPrize("Ordinary", 2, true)
+
+	expectedPrize := "no prize"
+
+	if p != expectedPrize {
+		t.Errorf("expecting prize: %s, got %s instead", expectedPrize, p)
+	}
+
+}
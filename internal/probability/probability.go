@@ -0,0 +1,156 @@
+package probability
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// Probability is an exact probability, given as a reduced fraction and as a
+// decimal. OneIn gives it as odds of 1 in OneIn, and is zero for impossible
+// outcomes.
+type Probability struct {
+	Fraction string  `json:"fraction"`
+	Decimal  float64 `json:"decimal"`
+	OneIn    float64 `json:"oneIn,omitempty"`
+	rat      *big.Rat
+}
+
+func newProbability(r *big.Rat) Probability {
+	p := Probability{Fraction: r.String(), rat: r}
+	p.Decimal, _ = r.Float64()
+
+	if r.Sign() > 0 {
+		p.OneIn, _ = new(big.Rat).Inv(r).Float64()
+	}
+	return p
+}
+
+// Rat returns the probability as an exact fraction.
+func (p Probability) Rat() *big.Rat {
+	if p.rat == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(p.rat)
+}
+
+// Outcome is one way a draw can turn out for a bet: how many of its numbers
+// are winning numbers and whether the additional number is one of them.
+type Outcome struct {
+	NumbersMatched      int              `json:"numbersMatched"`
+	HasAdditionalNumber bool             `json:"hasAdditionalNumber"`
+	Prize               string           `json:"prize"`
+	PrizeDetail         prizetable.Prize `json:"prizeDetail"`
+	Probability         Probability      `json:"probability"`
+}
+
+// Odds lists every outcome of a bet type with its probability. Outcomes
+// cover every draw, so their probabilities add up to 1.
+type Odds struct {
+	BetType  string      `json:"betType"`
+	Outcomes []Outcome   `json:"outcomes"`
+	AnyPrize Probability `json:"anyPrize"`
+}
+
+// ForBetType computes the odds of a bet type as named by Bet.GetBetType, in
+// a game with numbers from 1 to maxNumber and the prizes of table.
+func ForBetType(table *prizetable.Table, betType string, maxNumber int) (Odds, error) {
+	bet, err := ExampleBet(betType)
+	if err != nil {
+		return Odds{}, err
+	}
+	return ForBet(table, bet, maxNumber), nil
+}
+
+// ForBet computes the odds of a bet. Only the type of the bet matters, not
+// its numbers.
+//
+// Of the C(maxNumber, 6) ways to draw the winning numbers, C(k, m) *
+// C(maxNumber-k, 6-m) match m of the k numbers of the bet. The additional
+// number is then drawn from the other maxNumber-6 numbers, k-m of which are
+// in the bet.
+func ForBet(table *prizetable.Table, bet totodraw.Bet, maxNumber int) Odds {
+	betType := bet.GetBetType()
+	k := len(bet.Numbers())
+	others := int64(maxNumber - 6)
+	draws := new(big.Int).Mul(binomial(maxNumber, 6), big.NewInt(others))
+
+	odds := Odds{BetType: betType}
+	anyPrize := new(big.Rat)
+
+	for m := 0; m <= k && m <= 6; m++ {
+		ways := new(big.Int).Mul(binomial(k, m), binomial(maxNumber-k, 6-m))
+		if ways.Sign() == 0 {
+			continue
+		}
+
+		for _, hasAdditionalNumber := range []bool{false, true} {
+			additional := int64(k - m)
+			if !hasAdditionalNumber {
+				additional = others - additional
+			}
+
+			if additional == 0 {
+				continue
+			}
+
+			p := new(big.Rat).SetFrac(new(big.Int).Mul(ways, big.NewInt(additional)), draws)
+
+			o := Outcome{
+				NumbersMatched:      m,
+				HasAdditionalNumber: hasAdditionalNumber,
+				Probability:         newProbability(p),
+			}
+
+			if bet.IsSystemRoll() {
+				o.PrizeDetail = table.DeriveRollPrize(maxNumber, m, hasAdditionalNumber)
+				o.Prize = table.GetRollPrize(maxNumber, m, hasAdditionalNumber)
+			} else {
+				o.PrizeDetail = table.GetPrizeDetail(betType, m, hasAdditionalNumber)
+				o.Prize = table.GetPrize(betType, m, hasAdditionalNumber)
+			}
+
+			if o.PrizeDetail.Won {
+				anyPrize.Add(anyPrize, p)
+			}
+			odds.Outcomes = append(odds.Outcomes, o)
+		}
+	}
+
+	odds.AnyPrize = newProbability(anyPrize)
+	return odds
+}
+
+// ExampleBet returns a bet of the type named by Bet.GetBetType.
+func ExampleBet(betType string) (totodraw.Bet, error) {
+	size := 0
+	switch {
+	case betType == "Ordinary":
+		size = 6
+	case betType == "System Roll":
+		return totodraw.Bet{totodraw.RollNumber, 1, 2, 3, 4, 5}, nil
+	case strings.HasPrefix(betType, "System "):
+		size, _ = strconv.Atoi(strings.TrimPrefix(betType, "System "))
+	}
+
+	bet := make(totodraw.Bet, size)
+	for i := range bet {
+		bet[i] = i + 1
+	}
+
+	if size == 0 || bet.GetBetType() != betType {
+		return nil, apperror.Errorf(apperror.BadBetSize, "unknown bet type: %s", betType)
+	}
+	return bet, nil
+}
+
+func binomial(n int, k int) *big.Int {
+	if k < 0 || n < 0 || k > n {
+		return new(big.Int)
+	}
+	return new(big.Int).Binomial(int64(n), int64(k))
+}
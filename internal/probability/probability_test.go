@@ -0,0 +1,103 @@
+package probability
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+)
+
+func current(t *testing.T) *prizetable.Table {
+	table, ok := prizetable.Structure(prizetable.Current)
+	if !ok {
+		t.Fatal("missing current prize structure")
+	}
+	return table
+}
+
+func outcome(odds Odds, m int, hasAdditionalNumber bool) Outcome {
+	for _, o := range odds.Outcomes {
+		if o.NumbersMatched == m && o.HasAdditionalNumber == hasAdditionalNumber {
+			return o
+		}
+	}
+	return Outcome{}
+}
+
+func TestOrdinaryOdds(t *testing.T) {
+	odds, err := ForBetType(current(t), "Ordinary", 49)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	// Group 1 to Group 4 odds are those published by Singapore Pools: 1 in
+	// 13,983,816, 2,330,636, 55,491 and 22,197.
+	tests := []struct {
+		m                   int
+		hasAdditionalNumber bool
+		fraction            string
+		prize               string
+	}{
+		{6, false, "1/13983816", "Group 1"},
+		{5, true, "1/2330636", "Group 2"},
+		{5, false, "3/166474", "Group 3"},
+		{4, true, "15/332948", "Group 4"},
+		{4, false, "615/665896", "Group 5"},
+		{3, true, "205/166474", "Group 6"},
+		{3, false, "4100/249711", "Group 7"},
+	}
+
+	for _, test := range tests {
+		o := outcome(odds, test.m, test.hasAdditionalNumber)
+		if o.Probability.Fraction != test.fraction || o.Prize != test.prize {
+			t.Errorf("was expecting %s for %s but got %+v instead", test.fraction, test.prize, o)
+		}
+	}
+
+	expectedAny := "4654/249711"
+	if odds.AnyPrize.Fraction != expectedAny {
+		t.Errorf("was expecting any prize %s but got %s instead", expectedAny, odds.AnyPrize.Fraction)
+	}
+}
+
+func TestOddsAddUpToOne(t *testing.T) {
+	for _, betType := range []string{"Ordinary", "System 7", "System 9", "System 12", "System Roll"} {
+		odds, err := ForBetType(current(t), betType, 49)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+
+		total := new(big.Rat)
+		for _, o := range odds.Outcomes {
+			total.Add(total, o.Probability.Rat())
+		}
+
+		if total.Cmp(big.NewRat(1, 1)) != 0 {
+			t.Errorf("was expecting the odds of %s to add up to 1 but got %s instead", betType, total.String())
+		}
+	}
+}
+
+func TestSystemRollOdds(t *testing.T) {
+	odds, err := ForBetType(current(t), "System Roll", 49)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	// Every draw whose winning numbers include the 5 chosen ones wins
+	// Group 1 through the rolled number.
+	o := outcome(odds, 5, false)
+	if o.Probability.Fraction != "1/317814" || o.PrizeDetail.Group1 != 1 {
+		t.Errorf("unexpected outcome %+v", o)
+	}
+}
+
+func TestUnknownBetType(t *testing.T) {
+	for _, betType := range []string{"System 13", "System 5", "Quick Pick", ""} {
+		_, err := ForBetType(current(t), betType, 49)
+		if apperror.CodeOf(err) != apperror.BadBetSize {
+			t.Errorf("was expecting %s for %q but got %v instead", apperror.BadBetSize, betType, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ruleset
+
+import (
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// Ruleset holds the game rules in force from a date, and from a draw number
+// when it is known.
+type Ruleset struct {
+	Name            string
+	EffectiveFrom   time.Time
+	FirstDrawNumber int
+	MinNumber       int
+	MaxNumber       int
+	BetSizes        []int
+	PrizeStructure  string
+}
+
+// Registry holds rulesets in the order they came into force.
+type Registry []Ruleset
+
+// Default holds the rules of the 6/45 game and of the 6/49 game that replaced
+// it on 7 October 2014, from draw 3001.
+var Default = Registry{
+	{
+		Name:           "6/45",
+		MinNumber:      1,
+		MaxNumber:      45,
+		BetSizes:       []int{6, 7, 8, 9, 10, 11, 12},
+		PrizeStructure: prizetable.Legacy,
+	},
+	{
+		Name:            "6/49",
+		EffectiveFrom:   time.Date(2014, time.October, 7, 0, 0, 0, 0, time.UTC),
+		FirstDrawNumber: 3001,
+		MinNumber:       1,
+		MaxNumber:       49,
+		BetSizes:        []int{6, 7, 8, 9, 10, 11, 12},
+		PrizeStructure:  prizetable.Current,
+	},
+}
+
+// Latest returns the ruleset in force today.
+func (r Registry) Latest() Ruleset {
+	return r[len(r)-1]
+}
+
+// ForDate returns the ruleset in force on the given date.
+func (r Registry) ForDate(d time.Time) (Ruleset, error) {
+	for i := len(r) - 1; i >= 0; i-- {
+		if !d.Before(r[i].EffectiveFrom) {
+			return r[i], nil
+		}
+	}
+
+	return Ruleset{}, apperror.Errorf(apperror.InvalidDrawDate, "no ruleset in force on %s", d.Format("2006-01-02"))
+}
+
+// ForDrawNumber returns the ruleset in force for the given draw number. Every
+// ruleset after the first needs a FirstDrawNumber for the lookup to work, and
+// a registry missing one fails with an Internal error.
+func (r Registry) ForDrawNumber(n int) (Ruleset, error) {
+	for i := len(r) - 1; i > 0; i-- {
+		if r[i].FirstDrawNumber == 0 {
+			return Ruleset{}, apperror.Errorf(apperror.Internal, "first draw number of ruleset %s is unknown", r[i].Name)
+		}
+
+		if n >= r[i].FirstDrawNumber {
+			return r[i], nil
+		}
+	}
+
+	return r[0], nil
+}
+
+func (r Ruleset) AllowsBetSize(size int) bool {
+	for _, s := range r.BetSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateBet checks that the bet has a size the ruleset allows and that all
+// of its numbers are within the ruleset's range.
+func (r Ruleset) ValidateBet(b totodraw.Bet) error {
+	numbers := b.Numbers()
+
+	if b.IsSystemRoll() {
+		if len(numbers) != 5 {
+			return apperror.Errorf(apperror.BadBetSize, "system roll bets should contain 5 numbers, got %d", len(numbers))
+		}
+	} else if !r.AllowsBetSize(len(numbers)) {
+		return apperror.Errorf(apperror.BadBetSize, "bets should contain %d to %d numbers, got %d", r.BetSizes[0], r.BetSizes[len(r.BetSizes)-1], len(numbers))
+	}
+
+	return r.ValidateNumbers(numbers)
+}
+
+// ValidateNumbers checks that the numbers are within the range of the game
+// and that none of them is given twice.
+func (r Ruleset) ValidateNumbers(numbers []int) error {
+	seen := make(map[int]bool, len(numbers))
+	for _, n := range numbers {
+		if n < r.MinNumber || n > r.MaxNumber {
+			return apperror.Errorf(apperror.InvalidNumber, "number not within range: %d", n)
+		}
+
+		if seen[n] {
+			return apperror.Errorf(apperror.DuplicateNumber, "duplicate number %d", n)
+		}
+		seen[n] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package ruleset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+func TestForDateCurrent(t *testing.T) {
+	d := time.Date(2024, time.October, 14, 0, 0, 0, 0, time.UTC)
+
+	r, err := Default.ForDate(d)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expectedName := "6/49"
+	if r.Name != expectedName {
+		t.Errorf("expecting ruleset: %s, got %s instead", expectedName, r.Name)
+	}
+}
+
+func TestForDateBeforeChange(t *testing.T) {
+	d := time.Date(2014, time.October, 6, 0, 0, 0, 0, time.UTC)
+
+	r, err := Default.ForDate(d)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expectedName := "6/45"
+	if r.Name != expectedName {
+		t.Errorf("expecting ruleset: %s, got %s instead", expectedName, r.Name)
+	}
+
+	expectedMaxNumber := 45
+	if r.MaxNumber != expectedMaxNumber {
+		t.Errorf("expecting max number: %d, got %d instead", expectedMaxNumber, r.MaxNumber)
+	}
+}
+
+func TestForDrawNumber(t *testing.T) {
+	registry := Registry{
+		{Name: "old"},
+		{Name: "new", FirstDrawNumber: 100},
+	}
+
+	r, err := registry.ForDrawNumber(99)
+	if err != nil || r.Name != "old" {
+		t.Errorf("expecting ruleset: old, got %s and %v instead", r.Name, err)
+	}
+
+	r, err = registry.ForDrawNumber(100)
+	if err != nil || r.Name != "new" {
+		t.Errorf("expecting ruleset: new, got %s and %v instead", r.Name, err)
+	}
+}
+
+func TestDefaultForDrawNumber(t *testing.T) {
+	tests := []struct {
+		drawNumber int
+		expected   string
+	}{
+		{1, "6/45"},
+		{3000, "6/45"},
+		{3001, "6/49"},
+		{4000, "6/49"},
+	}
+
+	for _, test := range tests {
+		r, err := Default.ForDrawNumber(test.drawNumber)
+		if err != nil || r.Name != test.expected {
+			t.Errorf("expecting ruleset %s for draw %d, got %s and %v instead", test.expected, test.drawNumber, r.Name, err)
+		}
+	}
+}
+
+func TestForDrawNumberUnknown(t *testing.T) {
+	registry := Registry{
+		{Name: "old"},
+		{Name: "new"},
+	}
+
+	_, err := registry.ForDrawNumber(3912)
+	if apperror.CodeOf(err) != apperror.Internal {
+		t.Errorf("expecting an internal error looking up a draw number without first draw numbers, got %v instead", err)
+	}
+}
+
+func TestAllowsBetSize(t *testing.T) {
+	r := Default.Latest()
+
+	if !r.AllowsBetSize(12) {
+		t.Errorf("expecting bet size 12 to be allowed")
+	}
+
+	if r.AllowsBetSize(13) {
+		t.Errorf("expecting bet size 13 to not be allowed")
+	}
+}
+
+func TestValidateBetTooFewNumbers(t *testing.T) {
+	err := Default.Latest().ValidateBet(totodraw.Bet{1, 2, 3, 4, 5})
+
+	expectedError := "bets should contain 6 to 12 numbers, got 5"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+}
+
+func TestValidateBetTooManyNumbers(t *testing.T) {
+	err := Default.Latest().ValidateBet(totodraw.Bet{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13})
+
+	expectedError := "bets should contain 6 to 12 numbers, got 13"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+}
+
+func TestValidateBetSystemRoll(t *testing.T) {
+	r := Default.Latest()
+
+	if err := r.ValidateBet(totodraw.Bet{totodraw.RollNumber, 1, 2, 3, 4, 5}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err := r.ValidateBet(totodraw.Bet{totodraw.RollNumber, 1, 2, 3, 4})
+
+	expectedError := "system roll bets should contain 5 numbers, got 4"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+}
+
+func TestValidateBetOutOfRange(t *testing.T) {
+	r, _ := Default.ForDate(time.Date(2010, time.January, 4, 0, 0, 0, 0, time.UTC))
+
+	err := r.ValidateBet(totodraw.Bet{1, 2, 3, 4, 5, 49})
+
+	expectedError := "number not within range: 49"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+}
+
+func TestValidateBetDuplicateNumber(t *testing.T) {
+	err := Default.Latest().ValidateBet(totodraw.Bet{1, 2, 3, 4, 5, 5})
+
+	expectedError := "duplicate number 5"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expecting error: %s, got %v instead", expectedError, err)
+	}
+}
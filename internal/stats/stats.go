@@ -0,0 +1,216 @@
+package stats
+
+import (
+	"sort"
+
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+)
+
+// sumBucketWidth is the width of the buckets of the sum distribution.
+const sumBucketWidth = 10
+
+// Stats describes the winning numbers of a run of draws.
+type Stats struct {
+	Draws          int            `json:"draws"`
+	FromDrawNumber int            `json:"fromDrawNumber,omitempty"`
+	ToDrawNumber   int            `json:"toDrawNumber,omitempty"`
+	Numbers        []NumberStats  `json:"numbers"`
+	Pairs          []Combination  `json:"pairs"`
+	Triples        []Combination  `json:"triples"`
+	OddEven        []OddEvenSplit `json:"oddEven"`
+	HighLow        []HighLowSplit `json:"highLow"`
+	Sums           SumStats       `json:"sums"`
+}
+
+// NumberStats counts how often a number was drawn, as a winning number and
+// as the additional number. DrawsSince counts the draws since it was last
+// drawn as either, or all of them when it never was.
+type NumberStats struct {
+	Number         int `json:"number"`
+	Winning        int `json:"winning"`
+	Additional     int `json:"additional"`
+	Total          int `json:"total"`
+	DrawsSince     int `json:"drawsSince"`
+	LastDrawNumber int `json:"lastDrawNumber,omitempty"`
+}
+
+// Combination counts the draws whose winning numbers include all of Numbers.
+type Combination struct {
+	Numbers []int `json:"numbers"`
+	Draws   int   `json:"draws"`
+}
+
+// OddEvenSplit counts the draws with Odd odd and Even even winning numbers.
+type OddEvenSplit struct {
+	Odd   int `json:"odd"`
+	Even  int `json:"even"`
+	Draws int `json:"draws"`
+}
+
+// HighLowSplit counts the draws with High high and Low low winning numbers.
+// Low numbers are those up to half the highest number, so 1 to 24 in a game
+// of 49.
+type HighLowSplit struct {
+	High  int `json:"high"`
+	Low   int `json:"low"`
+	Draws int `json:"draws"`
+}
+
+// SumStats describes the sums of the winning numbers of each draw.
+type SumStats struct {
+	Min     int         `json:"min"`
+	Max     int         `json:"max"`
+	Mean    float64     `json:"mean"`
+	Buckets []SumBucket `json:"buckets"`
+}
+
+// SumBucket counts the draws whose winning numbers add up to From to To.
+type SumBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Draws int `json:"draws"`
+}
+
+// Compute computes the statistics of draws ordered by draw number, in a game
+// with numbers from 1 to maxNumber. Only the top most common pairs and
+// triples are kept.
+func Compute(draws []drawstore.Draw, maxNumber int, top int) Stats {
+	s := Stats{
+		Draws:   len(draws),
+		Numbers: make([]NumberStats, maxNumber),
+		OddEven: make([]OddEvenSplit, 7),
+		HighLow: make([]HighLowSplit, 7),
+	}
+
+	for i := range s.Numbers {
+		s.Numbers[i] = NumberStats{Number: i + 1, DrawsSince: len(draws)}
+	}
+
+	for i := range s.OddEven {
+		s.OddEven[i] = OddEvenSplit{Odd: 6 - i, Even: i}
+		s.HighLow[i] = HighLowSplit{High: 6 - i, Low: i}
+	}
+
+	if len(draws) > 0 {
+		s.FromDrawNumber = draws[0].DrawNumber
+		s.ToDrawNumber = draws[len(draws)-1].DrawNumber
+	}
+
+	pairs := make(map[[3]int]int)
+	triples := make(map[[3]int]int)
+	sums := make(map[int]int)
+	total := 0
+
+	for i, d := range draws {
+		drawsSince := len(draws) - 1 - i
+		odd, high, sum := 0, 0, 0
+
+		for _, n := range d.WinningNumbers {
+			if number := s.number(n); number != nil {
+				number.Winning++
+				number.seen(d.DrawNumber, drawsSince)
+			}
+
+			if n%2 == 1 {
+				odd++
+			}
+			if n > maxNumber/2 {
+				high++
+			}
+			sum += n
+		}
+
+		if number := s.number(d.AdditionalNumber); number != nil {
+			number.Additional++
+			number.seen(d.DrawNumber, drawsSince)
+		}
+
+		w := d.WinningNumbers
+		for a := 0; a < len(w); a++ {
+			for b := a + 1; b < len(w); b++ {
+				pairs[[3]int{w[a], w[b]}]++
+				for c := b + 1; c < len(w); c++ {
+					triples[[3]int{w[a], w[b], w[c]}]++
+				}
+			}
+		}
+
+		s.OddEven[6-odd].Draws++
+		s.HighLow[6-high].Draws++
+
+		sums[sum]++
+		total += sum
+		if i == 0 || sum < s.Sums.Min {
+			s.Sums.Min = sum
+		}
+		if sum > s.Sums.Max {
+			s.Sums.Max = sum
+		}
+	}
+
+	for i := range s.Numbers {
+		s.Numbers[i].Total = s.Numbers[i].Winning + s.Numbers[i].Additional
+	}
+
+	s.Pairs = topCombinations(pairs, 2, top)
+	s.Triples = topCombinations(triples, 3, top)
+
+	s.Sums.Buckets = []SumBucket{}
+	if len(draws) > 0 {
+		s.Sums.Mean = float64(total) / float64(len(draws))
+
+		for from := s.Sums.Min / sumBucketWidth * sumBucketWidth; from <= s.Sums.Max; from += sumBucketWidth {
+			b := SumBucket{From: from, To: from + sumBucketWidth - 1}
+			for sum := b.From; sum <= b.To; sum++ {
+				b.Draws += sums[sum]
+			}
+			s.Sums.Buckets = append(s.Sums.Buckets, b)
+		}
+	}
+
+	return s
+}
+
+func (s *Stats) number(n int) *NumberStats {
+	if n < 1 || n > len(s.Numbers) {
+		return nil
+	}
+	return &s.Numbers[n-1]
+}
+
+// seen records that the number was drawn in a draw followed by drawsSince
+// draws. Draws are seen in order, so the last one seen is the latest.
+func (n *NumberStats) seen(drawNumber int, drawsSince int) {
+	n.LastDrawNumber = drawNumber
+	n.DrawsSince = drawsSince
+}
+
+// topCombinations returns the top most common combinations of size numbers,
+// most common first and in number order among equals. Pairs are counted
+// with a zero third number.
+func topCombinations(counts map[[3]int]int, size int, top int) []Combination {
+	combinations := make([]Combination, 0, len(counts))
+	for k, count := range counts {
+		numbers := append([]int{}, k[:size]...)
+		combinations = append(combinations, Combination{Numbers: numbers, Draws: count})
+	}
+
+	sort.Slice(combinations, func(i, j int) bool {
+		a, b := combinations[i], combinations[j]
+		if a.Draws != b.Draws {
+			return a.Draws > b.Draws
+		}
+
+		for k := range a.Numbers {
+			if a.Numbers[k] != b.Numbers[k] {
+				return a.Numbers[k] < b.Numbers[k]
+			}
+		}
+		return false
+	})
+
+	if len(combinations) > top {
+		combinations = combinations[:top]
+	}
+	return combinations
+}
@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+func draw(drawNumber int, additional int, numbers ...int) drawstore.Draw {
+	return drawstore.Draw{
+		DrawNumber: drawNumber,
+		TotoDraw: totodraw.TotoDraw{
+			WinningNumbers:   numbers,
+			AdditionalNumber: additional,
+		},
+	}
+}
+
+var draws = []drawstore.Draw{
+	draw(1, 7, 1, 2, 3, 4, 5, 6),
+	draw(2, 1, 2, 3, 4, 30, 31, 49),
+	draw(3, 8, 2, 3, 10, 20, 40, 41),
+}
+
+func TestComputeNumbers(t *testing.T) {
+	s := Compute(draws, 49, 3)
+
+	if s.Draws != 3 || s.FromDrawNumber != 1 || s.ToDrawNumber != 3 || len(s.Numbers) != 49 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	tests := []NumberStats{
+		{Number: 1, Winning: 1, Additional: 1, Total: 2, DrawsSince: 1, LastDrawNumber: 2},
+		{Number: 2, Winning: 3, Total: 3, DrawsSince: 0, LastDrawNumber: 3},
+		{Number: 7, Additional: 1, Total: 1, DrawsSince: 2, LastDrawNumber: 1},
+		{Number: 9, DrawsSince: 3},
+	}
+
+	for _, expected := range tests {
+		actual := s.Numbers[expected.Number-1]
+		if actual != expected {
+			t.Errorf("was expecting %+v but got %+v instead", expected, actual)
+		}
+	}
+}
+
+func TestComputeCombinations(t *testing.T) {
+	s := Compute(draws, 49, 3)
+
+	expectedPairs := []Combination{
+		{Numbers: []int{2, 3}, Draws: 3},
+		{Numbers: []int{2, 4}, Draws: 2},
+		{Numbers: []int{3, 4}, Draws: 2},
+	}
+	if !reflect.DeepEqual(s.Pairs, expectedPairs) {
+		t.Errorf("was expecting %+v but got %+v instead", expectedPairs, s.Pairs)
+	}
+
+	expectedTriples := []Combination{
+		{Numbers: []int{2, 3, 4}, Draws: 2},
+		{Numbers: []int{1, 2, 3}, Draws: 1},
+		{Numbers: []int{1, 2, 4}, Draws: 1},
+	}
+	if !reflect.DeepEqual(s.Triples, expectedTriples) {
+		t.Errorf("was expecting %+v but got %+v instead", expectedTriples, s.Triples)
+	}
+}
+
+func TestComputeSplits(t *testing.T) {
+	s := Compute(draws, 49, 3)
+
+	// Odd numbers: 3, 3 and 2. High numbers, above 24: 0, 3 and 2.
+	if s.OddEven[3] != (OddEvenSplit{Odd: 3, Even: 3, Draws: 2}) || s.OddEven[4] != (OddEvenSplit{Odd: 2, Even: 4, Draws: 1}) {
+		t.Errorf("unexpected odd/even splits %+v", s.OddEven)
+	}
+
+	if s.HighLow[6].Draws != 1 || s.HighLow[3].Draws != 1 || s.HighLow[4].Draws != 1 {
+		t.Errorf("unexpected high/low splits %+v", s.HighLow)
+	}
+}
+
+func TestComputeSums(t *testing.T) {
+	s := Compute(draws, 49, 3)
+
+	// Sums: 21, 119 and 116.
+	if s.Sums.Min != 21 || s.Sums.Max != 119 || s.Sums.Mean != 256.0/3 {
+		t.Errorf("unexpected sums %+v", s.Sums)
+	}
+
+	if len(s.Sums.Buckets) != 10 || s.Sums.Buckets[0] != (SumBucket{From: 20, To: 29, Draws: 1}) || s.Sums.Buckets[9] != (SumBucket{From: 110, To: 119, Draws: 2}) {
+		t.Errorf("unexpected sum buckets %+v", s.Sums.Buckets)
+	}
+}
+
+func TestComputeNoDraws(t *testing.T) {
+	s := Compute(nil, 49, 3)
+
+	if s.Draws != 0 || len(s.Pairs) != 0 || len(s.Sums.Buckets) != 0 || s.Numbers[0].DrawsSince != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
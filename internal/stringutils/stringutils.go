@@ -1,28 +1,145 @@
 package stringutils
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
-	"strings"
+	"unicode"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+)
+
+const (
+	MinNumber = 1
+	MaxNumber = 49
 )
 
+// Token is a piece of input between separators, along with the column, counted
+// in characters from 1, where it starts.
+type Token struct {
+	Text   string
+	Column int
+}
+
+// ParseError is an error about the token at Column. It keeps the error code
+// of the error it wraps.
+type ParseError struct {
+	Column int
+	err    error
+}
+
+func (e *ParseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// ColumnOf returns the column of the token err is about, or 0 when err is not
+// about a single token.
+func ColumnOf(err error) int {
+	var e *ParseError
+	if errors.As(err, &e) {
+		return e.Column
+	}
+	return 0
+}
+
+// IndexError is an error about the number at Index of a list of numbers. It
+// keeps the error code of the error it wraps.
+type IndexError struct {
+	Index int
+	err   error
+}
+
+func (e *IndexError) Error() string {
+	return e.err.Error()
+}
+
+func (e *IndexError) Unwrap() error {
+	return e.err
+}
+
+// IndexOf returns the index of the number err is about, and false when err is
+// not about a single number of a list.
+func IndexOf(err error) (int, bool) {
+	var e *IndexError
+	if errors.As(err, &e) {
+		return e.Index, true
+	}
+	return 0, false
+}
+
+// IsSeparator reports whether r separates numbers: any whitespace, including
+// tabs and newlines, commas and dashes.
+func IsSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ',' || r == '-'
+}
+
+// Tokenize splits str into tokens, treating runs of separators as one.
+func Tokenize(str string) []Token {
+	var tokens []Token
+	var current []rune
+	start := 0
+
+	column := 0
+	for _, r := range str {
+		column++
+		if IsSeparator(r) {
+			if len(current) > 0 {
+				tokens = append(tokens, Token{Text: string(current), Column: start})
+				current = current[:0]
+			}
+			continue
+		}
+
+		if len(current) == 0 {
+			start = column
+		}
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		tokens = append(tokens, Token{Text: string(current), Column: start})
+	}
+
+	return tokens
+}
+
 func ConvertStringToUniqueSortedNumbers(str string) ([]int, error) {
-	trimmed := strings.Trim(str, " ")
-	split := strings.Split(trimmed, " ")
+	return ConvertStringToUniqueSortedNumbersInRange(str, MinNumber, MaxNumber)
+}
+
+// ConvertStringToUniqueSortedNumbersInRange is ConvertStringToUniqueSortedNumbers
+// for games whose numbers run from min to max.
+func ConvertStringToUniqueSortedNumbersInRange(str string, min int, max int) ([]int, error) {
+	tokens := Tokenize(str)
+	split := make([]string, len(tokens))
+	for i, t := range tokens {
+		split[i] = t.Text
+	}
+
 	numberMap := make(map[int]int, len(split))
 
 	var numbers []int
 
-	for _, s := range split {
-		num, err := ConvertStringToNumber(s)
+	for _, t := range tokens {
+		num, err := ConvertStringToNumberInRange(t.Text, min, max)
 		if err != nil {
-			return []int{}, fmt.Errorf("%s: %s", err.Error(), split)
+			return []int{}, &ParseError{
+				Column: t.Column,
+				err:    fmt.Errorf("%w at column %d: %s", err, t.Column, split),
+			}
 		}
 
 		_, ok := numberMap[num]
 		if ok {
-			return []int{}, fmt.Errorf("duplicate numbers found: %s", split)
+			return []int{}, &ParseError{
+				Column: t.Column,
+				err:    apperror.Errorf(apperror.DuplicateNumber, "duplicate numbers found at column %d: %s", t.Column, split),
+			}
 		}
 
 		numberMap[num] = 1
@@ -35,14 +152,48 @@ func ConvertStringToUniqueSortedNumbers(str string) ([]int, error) {
 	return numbers, nil
 }
 
+// UniqueSortedNumbersInRange checks that numbers, as given in a JSON array,
+// run from min to max without repeats, and returns them sorted. Errors point
+// at the index of the offending number.
+func UniqueSortedNumbersInRange(numbers []int, min int, max int) ([]int, error) {
+	seen := make(map[int]bool, len(numbers))
+	for i, num := range numbers {
+		if num < min || num > max {
+			return []int{}, &IndexError{
+				Index: i,
+				err:   apperror.Errorf(apperror.InvalidNumber, "number not within range: %d at index %d: %v", num, i, numbers),
+			}
+		}
+
+		if seen[num] {
+			return []int{}, &IndexError{
+				Index: i,
+				err:   apperror.Errorf(apperror.DuplicateNumber, "duplicate numbers found at index %d: %v", i, numbers),
+			}
+		}
+		seen[num] = true
+	}
+
+	sorted := append([]int(nil), numbers...)
+	sort.Ints(sorted)
+
+	return sorted, nil
+}
+
 func ConvertStringToNumber(str string) (int, error) {
+	return ConvertStringToNumberInRange(str, MinNumber, MaxNumber)
+}
+
+// ConvertStringToNumberInRange is ConvertStringToNumber for games whose
+// numbers run from min to max.
+func ConvertStringToNumberInRange(str string, min int, max int) (int, error) {
 	num, err := strconv.Atoi(str)
 	if err != nil {
-		return 0, fmt.Errorf("failed to convert %s", str)
+		return 0, apperror.Errorf(apperror.InvalidNumber, "failed to convert %s", str)
 	}
 
-	if num < 1 || num > 49 {
-		return 0, fmt.Errorf("number not within range: %s", str)
+	if num < min || num > max {
+		return 0, apperror.Errorf(apperror.InvalidNumber, "number not within range: %s", str)
 	}
 
 	return num, err
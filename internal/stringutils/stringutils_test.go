@@ -105,3 +105,107 @@ func TestConvertStringToNumberMoreThanFortyNine(t *testing.T) {
 		t.Errorf("expecting %s got %s instead", expected, err.Error())
 	}
 }
+
+func TestConvertStringToNumberInRangeMoreThanFortyFive(t *testing.T) {
+	input := "46"
+	_, err := ConvertStringToNumberInRange(input, 1, 45)
+	if err == nil {
+		t.Errorf("expecting err to be not nil")
+	}
+
+	expected := fmt.Sprintf("number not within range: %s", input)
+
+	if err.Error() != expected {
+		t.Errorf("expecting %s got %s instead", expected, err.Error())
+	}
+}
+
+func TestConvertStringToSortedNumbersSeparators(t *testing.T) {
+	expected := []int{3, 9, 28}
+
+	for _, input := range []string{"3,9,28", "3  9 28", "03-09-28", "3\t9\t28", "3\n9\r\n28", " 3, 9 ,28 "} {
+		numbers, err := ConvertStringToUniqueSortedNumbers(input)
+		if err != nil {
+			t.Errorf("unexpected error converting %q: %v", input, err)
+			continue
+		}
+
+		if len(numbers) != len(expected) {
+			t.Errorf("was expecting %v but got %v instead for %q", expected, numbers, input)
+			continue
+		}
+
+		for i, num := range expected {
+			if numbers[i] != num {
+				t.Errorf("was expecting %v but got %v instead for %q", expected, numbers, input)
+				break
+			}
+		}
+	}
+}
+
+func TestConvertStringToSortedNumbersErrorColumn(t *testing.T) {
+	input := "03, 09,  x8"
+	_, err := ConvertStringToUniqueSortedNumbers(input)
+	if err == nil {
+		t.Fatalf("expecting err to be not nil")
+	}
+
+	expectedColumn := 10
+	if ColumnOf(err) != expectedColumn {
+		t.Errorf("expecting column %d got %d instead", expectedColumn, ColumnOf(err))
+	}
+
+	expected := "failed to convert x8 at column 10: [03 09 x8]"
+	if err.Error() != expected {
+		t.Errorf("expecting %s got %s instead", expected, err.Error())
+	}
+}
+
+func TestUniqueSortedNumbersInRangeErrorIndex(t *testing.T) {
+	tests := []struct {
+		numbers  []int
+		index    int
+		expected string
+	}{
+		{[]int{1, 2, 3, 4, 5, 50}, 5, "number not within range: 50 at index 5: [1 2 3 4 5 50]"},
+		{[]int{0, 1, 2, 3, 4, 5}, 0, "number not within range: 0 at index 0: [0 1 2 3 4 5]"},
+		{[]int{1, 2, 3, 2, 4, 5}, 3, "duplicate numbers found at index 3: [1 2 3 2 4 5]"},
+	}
+
+	for _, test := range tests {
+		_, err := UniqueSortedNumbersInRange(test.numbers, MinNumber, MaxNumber)
+		if err == nil {
+			t.Fatalf("expecting err to be not nil for %v", test.numbers)
+		}
+
+		index, ok := IndexOf(err)
+		if !ok || index != test.index {
+			t.Errorf("expecting index %d got %d (%v) instead", test.index, index, ok)
+		}
+
+		if err.Error() != test.expected {
+			t.Errorf("expecting %s got %s instead", test.expected, err.Error())
+		}
+	}
+
+	numbers, err := UniqueSortedNumbersInRange([]int{9, 3, 28}, MinNumber, MaxNumber)
+	if err != nil || fmt.Sprint(numbers) != "[3 9 28]" {
+		t.Errorf("expecting [3 9 28] got %v (%v) instead", numbers, err)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("A. 03 09\t18")
+
+	expected := []Token{{"A.", 1}, {"03", 4}, {"09", 7}, {"18", 10}}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expecting %v got %v instead", expected, tokens)
+	}
+
+	for i, token := range expected {
+		if tokens[i] != token {
+			t.Errorf("expecting %v got %v instead", token, tokens[i])
+		}
+	}
+}
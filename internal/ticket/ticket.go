@@ -0,0 +1,248 @@
+package ticket
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/stringutils"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// Ticket is what Parse reads from the text of a physical or electronic
+// ticket. DrawDate is formatted as 2006-01-02 and is empty when the ticket
+// text has no date.
+type Ticket struct {
+	DrawNumber int     `json:"drawNumber,omitempty"`
+	DrawDate   string  `json:"drawDate,omitempty"`
+	DrawCount  int     `json:"drawCount"`
+	Boards     []Board `json:"boards"`
+}
+
+// Board is a single bet of a ticket, labelled A, B, C and so on.
+type Board struct {
+	Label   string       `json:"label"`
+	Numbers []int        `json:"numbers"`
+	BetType string       `json:"betType"`
+	Bet     totodraw.Bet `json:"-"`
+}
+
+var weekdays = map[string]bool{
+	"MON": true, "TUE": true, "WED": true, "THU": true, "FRI": true, "SAT": true, "SUN": true,
+}
+
+// board collects the numbers and bet type markers of a board as it is read.
+type board struct {
+	label      string
+	numbers    []string
+	systemSize int
+	systemRoll bool
+	ordinary   bool
+}
+
+// Parse reads the draw number and date, the number of draws and the boards
+// from ticket text such as:
+//
+//	DRAW: 3912 MON 14/10/24
+//	A. 03 09 18 28 32 46
+//	B. 01 02 03 04 05 06 07 08 SYS 8
+//	C. 01 02 03 04 05 SYS R
+//	4 DRAWS
+//
+// A board's numbers may continue on the following lines. Lines that are not
+// about the draw or a board, such as prices, are skipped.
+func Parse(text string) (Ticket, error) {
+	t := Ticket{DrawCount: 1}
+	var boards []*board
+	var current *board
+
+	for _, line := range strings.Split(text, "\n") {
+		tokens := stringutils.Tokenize(line)
+		if current != nil && !isContinuation(tokens) {
+			current = nil
+		}
+
+		for i := 0; i < len(tokens); i++ {
+			raw := tokens[i].Text
+			word := strings.ToUpper(strings.TrimSuffix(raw, ":"))
+			previous := ""
+			if i > 0 {
+				previous = strings.ToUpper(strings.TrimSuffix(tokens[i-1].Text, ":"))
+			}
+
+			switch {
+			case isBoardLabel(raw):
+				current = &board{label: strings.ToUpper(raw[:1])}
+				boards = append(boards, current)
+			case word == "DRAWS" || word == "DRAW" && previous == "MULTI":
+				current = nil
+				if n, err := strconv.Atoi(previous); err == nil {
+					t.DrawCount = n
+				} else if i+1 < len(tokens) {
+					if n, err := strconv.Atoi(tokens[i+1].Text); err == nil {
+						t.DrawCount = n
+						i++
+					}
+				}
+			case word == "DRAW":
+				current = nil
+				if i+1 < len(tokens) {
+					if n, err := strconv.Atoi(tokens[i+1].Text); err == nil {
+						t.DrawNumber = n
+						i++
+					}
+				}
+			case isDate(raw):
+				d, err := parseDate(raw)
+				if err != nil {
+					return Ticket{}, err
+				}
+				t.DrawDate = d
+			case weekdays[word]:
+			case current == nil:
+			case isNumber(raw):
+				current.numbers = append(current.numbers, raw)
+			case word == "SYS" || word == "SYSTEM":
+				if i+1 < len(tokens) {
+					next := strings.ToUpper(tokens[i+1].Text)
+					if n, err := strconv.Atoi(next); err == nil {
+						current.systemSize = n
+						i++
+					} else if next == "R" || next == "ROLL" {
+						current.systemRoll = true
+						i++
+					}
+				}
+			case word == "R" || word == "ROLL":
+				current.systemRoll = true
+			case word == "ORD" || word == "ORDINARY":
+				current.ordinary = true
+			default:
+				current = nil
+			}
+		}
+	}
+
+	if len(boards) == 0 {
+		return Ticket{}, apperror.New(apperror.InvalidTicket, "no boards found on ticket")
+	}
+
+	if t.DrawCount < 1 {
+		return Ticket{}, apperror.Errorf(apperror.InvalidTicket, "number of draws should be at least 1, got %d", t.DrawCount)
+	}
+
+	for _, b := range boards {
+		board, err := b.build()
+		if err != nil {
+			return Ticket{}, err
+		}
+		t.Boards = append(t.Boards, board)
+	}
+
+	return t, nil
+}
+
+// Bets returns the bets of every board, in board order.
+func (t Ticket) Bets() []totodraw.Bet {
+	bets := make([]totodraw.Bet, len(t.Boards))
+	for i, b := range t.Boards {
+		bets[i] = b.Bet
+	}
+	return bets
+}
+
+// build turns the board into a Board. Its numbers are only read here: whether
+// they are in range and given once depends on the rules of the draw, which
+// the caller checks board by board.
+func (b *board) build() (Board, error) {
+	numbers := make([]int, len(b.numbers))
+	for i, s := range b.numbers {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return Board{}, apperror.Errorf(apperror.InvalidNumber, "board %s: failed to convert %s", b.label, s)
+		}
+		numbers[i] = n
+	}
+	sort.Ints(numbers)
+	bet := totodraw.Bet(numbers)
+
+	expected := 0
+	switch {
+	case b.systemRoll:
+		expected = 5
+	case b.systemSize > 0:
+		expected = b.systemSize
+	case b.ordinary:
+		expected = 6
+	}
+
+	if expected > 0 && len(bet) != expected {
+		return Board{}, apperror.Errorf(apperror.BadBetSize, "board %s should have %d numbers, got %d", b.label, expected, len(bet))
+	}
+
+	if b.systemRoll {
+		bet = append(totodraw.Bet{totodraw.RollNumber}, bet...)
+	}
+
+	return Board{
+		Label:   b.label,
+		Numbers: numbers,
+		BetType: bet.GetBetType(),
+		Bet:     bet,
+	}, nil
+}
+
+// isContinuation reports whether a line only holds numbers and bet type
+// markers, and so continues the board of the line before it.
+func isContinuation(tokens []stringutils.Token) bool {
+	if len(tokens) == 0 || !isNumber(tokens[0].Text) {
+		return false
+	}
+
+	for _, t := range tokens {
+		switch strings.ToUpper(t.Text) {
+		case "SYS", "SYSTEM", "R", "ROLL", "ORD", "ORDINARY":
+			continue
+		}
+
+		if !isNumber(t.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBoardLabel(s string) bool {
+	if len(s) != 2 || (s[1] != '.' && s[1] != ')') {
+		return false
+	}
+	c := s[0]
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDate(s string) bool {
+	return strings.Count(s, "/") == 2
+}
+
+func parseDate(s string) (string, error) {
+	for _, layout := range []string{"2/1/06", "2/1/2006"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d.Format("2006-01-02"), nil
+		}
+	}
+	return "", apperror.Errorf(apperror.InvalidTicket, "unable to parse draw date %s", s)
+}
@@ -0,0 +1,126 @@
+package ticket
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+const sampleTicket = `TOTO
+DRAW: 3912 MON 14/10/24
+A. 03 09 18 28 32 46
+B. 01 02 03 04 05 06 07 08 SYS 8
+C. 01 02 03 04 05 SYS R
+4 DRAWS
+PRICE: $136.00`
+
+func TestParse(t *testing.T) {
+	ticket, err := Parse(sampleTicket)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if ticket.DrawNumber != 3912 {
+		t.Errorf("was expecting draw number 3912 but got %d instead", ticket.DrawNumber)
+	}
+
+	if ticket.DrawDate != "2024-10-14" {
+		t.Errorf("was expecting draw date 2024-10-14 but got %s instead", ticket.DrawDate)
+	}
+
+	if ticket.DrawCount != 4 {
+		t.Errorf("was expecting 4 draws but got %d instead", ticket.DrawCount)
+	}
+
+	expected := []Board{
+		{Label: "A", Numbers: []int{3, 9, 18, 28, 32, 46}, BetType: "Ordinary", Bet: totodraw.Bet{3, 9, 18, 28, 32, 46}},
+		{Label: "B", Numbers: []int{1, 2, 3, 4, 5, 6, 7, 8}, BetType: "System 8", Bet: totodraw.Bet{1, 2, 3, 4, 5, 6, 7, 8}},
+		{Label: "C", Numbers: []int{1, 2, 3, 4, 5}, BetType: "System Roll", Bet: totodraw.Bet{0, 1, 2, 3, 4, 5}},
+	}
+
+	if !reflect.DeepEqual(ticket.Boards, expected) {
+		t.Errorf("was expecting %+v but got %+v instead", expected, ticket.Boards)
+	}
+}
+
+func TestParseBoardOverSeveralLines(t *testing.T) {
+	ticket, err := Parse("A) 01 02 03 04 05\n06 07 08 09 10\nSYS 10")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(ticket.Boards) != 1 || ticket.Boards[0].BetType != "System 10" {
+		t.Errorf("was expecting a single System 10 board but got %+v instead", ticket.Boards)
+	}
+
+	if ticket.DrawCount != 1 {
+		t.Errorf("was expecting 1 draw but got %d instead", ticket.DrawCount)
+	}
+}
+
+func TestParseMultiDraw(t *testing.T) {
+	ticket, err := Parse("MULTI-DRAW: 6\nA. 1 2 3 4 5 6")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if ticket.DrawCount != 6 {
+		t.Errorf("was expecting 6 draws but got %d instead", ticket.DrawCount)
+	}
+
+	if ticket.DrawNumber != 0 {
+		t.Errorf("was expecting no draw number but got %d instead", ticket.DrawNumber)
+	}
+}
+
+func TestParseBets(t *testing.T) {
+	ticket, err := Parse(sampleTicket)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	bets := ticket.Bets()
+	if len(bets) != 3 || !bets[2].IsSystemRoll() {
+		t.Errorf("was expecting 3 bets ending with a System Roll but got %v instead", bets)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		code apperror.Code
+	}{
+		{"DRAW: 3912 MON 14/10/24", apperror.InvalidTicket},
+		{"DRAW: 3912 MON 34/10/24\nA. 1 2 3 4 5 6", apperror.InvalidTicket},
+		{"A. 1 2 3 4 5 6 SYS 8", apperror.BadBetSize},
+		{"A. 1 2 3 4 5 6 SYS R", apperror.BadBetSize},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.text)
+		if err == nil {
+			t.Errorf("was expecting an error parsing %q", test.text)
+			continue
+		}
+
+		if code := apperror.CodeOf(err); code != test.code {
+			t.Errorf("was expecting %s parsing %q but got %s instead", test.code, test.text, code)
+		}
+	}
+}
+
+func TestParseKeepsNumbersForTheRules(t *testing.T) {
+	ticket, err := Parse("A. 1 2 3 4 5 60\nB. 9 1 2 3 4 4")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := [][]int{{1, 2, 3, 4, 5, 60}, {1, 2, 3, 4, 4, 9}}
+	for i, numbers := range expected {
+		if !reflect.DeepEqual(ticket.Boards[i].Numbers, numbers) {
+			t.Errorf("was expecting board %s to have %v but got %v instead", ticket.Boards[i].Label, numbers, ticket.Boards[i].Numbers)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package totodraw
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+)
+
+// Mask is a set of numbers from 0 to 63 held as the bits of a uint64, bit n
+// standing for number n. A bet, including the RollNumber of a System Roll
+// bet, or the winning numbers of a draw fit in a single Mask, so matching
+// them takes a single AND and a popcount.
+type Mask uint64
+
+// MaxMaskNumber is the highest number a Mask can hold.
+const MaxMaskNumber = 63
+
+// NewMask returns the Mask of the numbers. It fails for numbers outside 0 to
+// MaxMaskNumber and for numbers given more than once, so that sorted
+// numbers convert back unchanged.
+func NewMask(numbers []int) (Mask, error) {
+	var m Mask
+	for _, n := range numbers {
+		if n < 0 || n > MaxMaskNumber {
+			return 0, apperror.New(apperror.InvalidNumber, fmt.Sprintf("number out of mask range: %d", n))
+		}
+
+		bit := Mask(1) << n
+		if m&bit != 0 {
+			return 0, apperror.New(apperror.DuplicateNumber, fmt.Sprintf("duplicate numbers found: %v", numbers))
+		}
+		m |= bit
+	}
+	return m, nil
+}
+
+// Mask returns the Mask of the bet.
+func (b Bet) Mask() (Mask, error) {
+	return NewMask(b)
+}
+
+// Mask returns the Mask of the winning numbers.
+func (w WinningNumbers) Mask() (Mask, error) {
+	return NewMask(w)
+}
+
+// Contains reports whether n is in the mask.
+func (m Mask) Contains(n int) bool {
+	return n >= 0 && n <= MaxMaskNumber && m&(Mask(1)<<n) != 0
+}
+
+// Len returns how many numbers are in the mask.
+func (m Mask) Len() int {
+	return bits.OnesCount64(uint64(m))
+}
+
+// Matches returns how many numbers the masks have in common.
+func (m Mask) Matches(o Mask) int {
+	return bits.OnesCount64(uint64(m & o))
+}
+
+// Numbers returns the numbers in the mask in ascending order.
+func (m Mask) Numbers() []int {
+	if m == 0 {
+		return nil
+	}
+
+	numbers := make([]int, 0, m.Len())
+	for rest := uint64(m); rest != 0; rest &= rest - 1 {
+		numbers = append(numbers, bits.TrailingZeros64(rest))
+	}
+	return numbers
+}
+
+// Bet returns the numbers in the mask as a Bet. The RollNumber sorts first,
+// so a System Roll bet converts back as a System Roll bet.
+func (m Mask) Bet() Bet {
+	return Bet(m.Numbers())
+}
+
+// WinningNumbers returns the numbers in the mask as WinningNumbers.
+func (m Mask) WinningNumbers() WinningNumbers {
+	return WinningNumbers(m.Numbers())
+}
+
+// DrawMask is a TotoDraw's numbers as masks, for matching many bets against
+// the same draw.
+type DrawMask struct {
+	WinningNumbers   Mask
+	AdditionalNumber Mask
+}
+
+// NewDrawMask returns the masks of the draw's winning and additional numbers.
+func NewDrawMask(t TotoDraw) (DrawMask, error) {
+	w, err := t.WinningNumbers.Mask()
+	if err != nil {
+		return DrawMask{}, err
+	}
+
+	a, err := NewMask([]int{t.AdditionalNumber})
+	if err != nil {
+		return DrawMask{}, err
+	}
+
+	return DrawMask{WinningNumbers: w, AdditionalNumber: a}, nil
+}
+
+// Match returns how many winning numbers the bet matched and whether it
+// matched the additional number.
+func (d DrawMask) Match(bet Mask) (int, bool) {
+	return bet.Matches(d.WinningNumbers), bet&d.AdditionalNumber != 0
+}
@@ -2,6 +2,9 @@ package totodraw
 
 import (
 	"fmt"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
 )
 
 type (
@@ -9,7 +12,29 @@ type (
 	WinningNumbers []int
 )
 
+// RollNumber stands in for the rolled sixth number of a System Roll bet. It
+// sorts before every valid number, so a System Roll bet is RollNumber
+// followed by its 5 chosen numbers.
+const RollNumber = 0
+
+func (b Bet) IsSystemRoll() bool {
+	return len(b) > 0 && b[0] == RollNumber
+}
+
+// Numbers returns the numbers chosen for the bet, leaving out the RollNumber
+// of a System Roll bet.
+func (b Bet) Numbers() []int {
+	if b.IsSystemRoll() {
+		return b[1:]
+	}
+	return b
+}
+
 func (b Bet) GetBetType() string {
+	if b.IsSystemRoll() {
+		return "System Roll"
+	}
+
 	length := len(b)
 	switch length {
 	case 6:
@@ -20,6 +45,88 @@ func (b Bet) GetBetType() string {
 	return "unknown"
 }
 
+// Combinations returns how many ordinary combinations the bet expands to,
+// C(n, 6) for a bet of n numbers. maxNumber is the highest number of the
+// game: a System Roll bet has one combination for every number it rolls over.
+func (b Bet) Combinations(maxNumber int) int {
+	if b.IsSystemRoll() {
+		return maxNumber - len(b.Numbers())
+	}
+
+	n := len(b)
+	if n < 6 {
+		return 0
+	}
+
+	count := 1
+	for k := 1; k <= 6; k++ {
+		count = count * (n - 6 + k) / k
+	}
+	return count
+}
+
+// CostCents returns the price of the bet, at unitPriceCents for each of its
+// ordinary combinations.
+func (b Bet) CostCents(maxNumber int, unitPriceCents int) int {
+	return b.Combinations(maxNumber) * unitPriceCents
+}
+
+// OrdinaryBets expands the bet into every ordinary 6-number combination it
+// contains, in lexicographic order. maxNumber is the highest number of the
+// game, which a System Roll bet rolls up to.
+func (b Bet) OrdinaryBets(maxNumber int) []Bet {
+	if b.IsSystemRoll() {
+		return b.rollBets(maxNumber)
+	}
+
+	var bets []Bet
+
+	indexes := []int{0, 1, 2, 3, 4, 5}
+	for len(b) >= 6 {
+		bet := make(Bet, 6)
+		for i, index := range indexes {
+			bet[i] = b[index]
+		}
+		bets = append(bets, bet)
+
+		i := 5
+		for i >= 0 && indexes[i] == len(b)-6+i {
+			i--
+		}
+		if i < 0 {
+			break
+		}
+
+		indexes[i]++
+		for j := i + 1; j < 6; j++ {
+			indexes[j] = indexes[j-1] + 1
+		}
+	}
+
+	return bets
+}
+
+func (b Bet) rollBets(maxNumber int) []Bet {
+	chosen := b[1:]
+
+	var bets []Bet
+	i := 0
+	for n := 1; n <= maxNumber; n++ {
+		if i < len(chosen) && chosen[i] == n {
+			i++
+			continue
+		}
+
+		bet := make(Bet, 0, 6)
+		bet = append(bet, chosen[:i]...)
+		bet = append(bet, n)
+		bet = append(bet, chosen[i:]...)
+		bets = append(bets, bet)
+	}
+
+	return bets
+}
+
 func (w WinningNumbers) Contains(i int) bool {
 	lo := 0
 	hi := len(w)
@@ -52,8 +159,10 @@ func (w WinningNumbers) IsValid() bool {
 }
 
 type TotoDraw struct {
-	WinningNumbers   WinningNumbers `json:"winningNumbers"`
-	AdditionalNumber int            `json:"additionalNumber"`
+	WinningNumbers   WinningNumbers          `json:"winningNumbers"`
+	AdditionalNumber int                     `json:"additionalNumber"`
+	GroupPrizes      *prizetable.GroupPrizes `json:"groupPrizes,omitempty"`
+	PrizeStructure   string                  `json:"prizeStructure,omitempty"`
 }
 
 type Request struct {
@@ -63,17 +172,33 @@ type Request struct {
 }
 
 type BetResult struct {
-	Numbers             []int  `json:"numbers"`
-	BetType             string `json:"betType"`
+	Index               int              `json:"index"`
+	Label               string           `json:"label,omitempty"`
+	Numbers             []int            `json:"numbers"`
+	BetType             string           `json:"betType"`
+	NumbersMatched      int              `json:"numbersMatched"`
+	HasAdditionalNumber bool             `json:"hasAdditionalNumber"`
+	Prize               string           `json:"prize"`
+	CostCents           int              `json:"costCents"`
+	PrizeDetail         prizetable.Prize `json:"prizeDetail"`
+	PayoutCents         *int             `json:"payoutCents,omitempty"`
+	Breakdown           []PrizeTier      `json:"breakdown,omitempty"`
+}
+
+// PrizeTier counts the ordinary combinations of a bet that won the same
+// Ordinary prize.
+type PrizeTier struct {
 	NumbersMatched      int    `json:"numbersMatched"`
 	HasAdditionalNumber bool   `json:"hasAdditionalNumber"`
 	Prize               string `json:"prize"`
+	Count               int    `json:"count"`
+	Combinations        []Bet  `json:"combinations,omitempty"`
 }
 
 func NewTotoDraw(w WinningNumbers, a int) (TotoDraw, error) {
 	for _, n := range w {
 		if n == a {
-			return TotoDraw{}, fmt.Errorf("duplicate number found in additional number")
+			return TotoDraw{}, apperror.New(apperror.DuplicateNumber, "duplicate number found in additional number")
 		}
 	}
 
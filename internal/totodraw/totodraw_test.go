@@ -1,6 +1,12 @@
 package totodraw
 
-import "testing"
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+)
 
 func TestWinningNumbersContains(t *testing.T) {
 	w := WinningNumbers{1, 2, 3, 4, 5, 6}
@@ -91,3 +97,257 @@ func TestGetBetTypeSystemTwelve(t *testing.T) {
 		t.Errorf("expecting type: %s,  got %s instead", expectedType, actualType)
 	}
 }
+
+func TestOrdinaryBetsSystemNine(t *testing.T) {
+	b := Bet{1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	bets := b.OrdinaryBets(49)
+
+	expectedCount := 84
+	if len(bets) != expectedCount {
+		t.Fatalf("expecting %d ordinary bets, got %d instead", expectedCount, len(bets))
+	}
+
+	expectedFirst := Bet{1, 2, 3, 4, 5, 6}
+	expectedLast := Bet{4, 5, 6, 7, 8, 9}
+	for i := range expectedFirst {
+		if bets[0][i] != expectedFirst[i] || bets[len(bets)-1][i] != expectedLast[i] {
+			t.Errorf("expecting first %v and last %v, got %v and %v instead", expectedFirst, expectedLast, bets[0], bets[len(bets)-1])
+			break
+		}
+	}
+}
+
+func TestOrdinaryBetsOrdinary(t *testing.T) {
+	b := Bet{1, 2, 3, 4, 5, 6}
+
+	bets := b.OrdinaryBets(49)
+
+	if len(bets) != 1 {
+		t.Errorf("expecting 1 ordinary bet, got %d instead", len(bets))
+	}
+}
+
+func TestGetBetTypeSystemRoll(t *testing.T) {
+	b := Bet{RollNumber, 1, 2, 3, 4, 5}
+
+	expectedType := "System Roll"
+	actualType := b.GetBetType()
+	if actualType != expectedType {
+		t.Errorf("expecting type: %s,  got %s instead", expectedType, actualType)
+	}
+}
+
+func TestOrdinaryBetsSystemRoll(t *testing.T) {
+	b := Bet{RollNumber, 3, 9, 28, 32, 46}
+
+	bets := b.OrdinaryBets(49)
+
+	expectedCount := 44
+	if len(bets) != expectedCount {
+		t.Fatalf("expecting %d ordinary bets, got %d instead", expectedCount, len(bets))
+	}
+
+	expectedBet := Bet{3, 9, 10, 28, 32, 46}
+	for i, n := range expectedBet {
+		if bets[7][i] != n {
+			t.Errorf("expecting bet %v, got %v instead", expectedBet, bets[7])
+			break
+		}
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	tests := []struct {
+		bet      Bet
+		expected int
+	}{
+		{Bet{1, 2, 3, 4, 5, 6}, 1},
+		{Bet{1, 2, 3, 4, 5, 6, 7}, 7},
+		{Bet{1, 2, 3, 4, 5, 6, 7, 8, 9}, 84},
+		{Bet{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, 924},
+		{Bet{RollNumber, 1, 2, 3, 4, 5}, 44},
+		{Bet{1, 2, 3, 4, 5}, 0},
+		{Bet{}, 0},
+	}
+
+	for _, test := range tests {
+		actual := test.bet.Combinations(49)
+		if actual != test.expected {
+			t.Errorf("expecting %d combinations for %v, got %d instead", test.expected, test.bet, actual)
+		}
+
+		if len(test.bet) >= 6 && len(test.bet.OrdinaryBets(49)) != actual {
+			t.Errorf("expecting %v to expand to %d ordinary bets", test.bet, actual)
+		}
+	}
+}
+
+func TestCostCents(t *testing.T) {
+	b := Bet{RollNumber, 1, 2, 3, 4, 5}
+
+	expectedCost := 44 * 50
+	actualCost := b.CostCents(49, 50)
+	if actualCost != expectedCost {
+		t.Errorf("expecting cost: %d, got %d instead", expectedCost, actualCost)
+	}
+
+	expectedCost = 40 * 100
+	actualCost = b.CostCents(45, 100)
+	if actualCost != expectedCost {
+		t.Errorf("expecting cost: %d, got %d instead", expectedCost, actualCost)
+	}
+}
+
+func TestMaskRoundTrip(t *testing.T) {
+	bets := []Bet{
+		{1, 2, 3, 4, 5, 6},
+		{3, 9, 18, 28, 32, 46, 49},
+		{RollNumber, 1, 2, 3, 4, 5},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 63},
+	}
+
+	for _, bet := range bets {
+		m, err := bet.Mask()
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", bet, err)
+		}
+
+		if m.Len() != len(bet) {
+			t.Errorf("expecting %d numbers in the mask of %v, got %d instead", len(bet), bet, m.Len())
+		}
+
+		if !reflect.DeepEqual(m.Bet(), bet) {
+			t.Errorf("expecting %v to convert back unchanged, got %v instead", bet, m.Bet())
+		}
+
+		if m.Bet().IsSystemRoll() != bet.IsSystemRoll() {
+			t.Errorf("expecting %v to stay a %s bet", bet, bet.GetBetType())
+		}
+	}
+
+	w := WinningNumbers{3, 9, 28, 32, 37, 46}
+	m, err := w.Mask()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(m.WinningNumbers(), w) {
+		t.Errorf("expecting %v to convert back unchanged, got %v instead", w, m.WinningNumbers())
+	}
+
+	if !m.Contains(37) || m.Contains(7) || m.Contains(64) || m.Contains(-1) {
+		t.Errorf("unexpected numbers in the mask of %v", w)
+	}
+}
+
+func TestNewMaskErrors(t *testing.T) {
+	tests := []struct {
+		numbers []int
+		code    apperror.Code
+	}{
+		{[]int{1, 2, 64}, apperror.InvalidNumber},
+		{[]int{-1, 2, 3}, apperror.InvalidNumber},
+		{[]int{1, 2, 2}, apperror.DuplicateNumber},
+	}
+
+	for _, test := range tests {
+		_, err := NewMask(test.numbers)
+		if apperror.CodeOf(err) != test.code {
+			t.Errorf("expecting %s for %v, got %v instead", test.code, test.numbers, err)
+		}
+	}
+}
+
+func TestDrawMaskMatch(t *testing.T) {
+	draw, _ := NewTotoDraw(WinningNumbers{3, 9, 28, 32, 37, 46}, 7)
+	d, err := NewDrawMask(draw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		bet := Bet(r.Perm(49)[:6+i%7])
+		for j := range bet {
+			bet[j]++
+		}
+		if i%10 == 0 {
+			bet = append(Bet{RollNumber}, bet[:5]...)
+		}
+
+		expectedCount := 0
+		expectedAdditional := false
+		for _, n := range bet {
+			if draw.WinningNumbers.Contains(n) {
+				expectedCount++
+			} else if n == draw.AdditionalNumber {
+				expectedAdditional = true
+			}
+		}
+
+		m, err := bet.Mask()
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", bet, err)
+		}
+
+		count, additional := d.Match(m)
+		if count != expectedCount || additional != expectedAdditional {
+			t.Errorf("expecting %v to match %d and %t, got %d and %t instead", bet, expectedCount, expectedAdditional, count, additional)
+		}
+	}
+}
+
+// benchmarkBets returns bets of 6 to 12 numbers to match against a draw.
+func benchmarkBets() []Bet {
+	r := rand.New(rand.NewSource(1))
+
+	bets := make([]Bet, 1024)
+	for i := range bets {
+		bet := Bet(r.Perm(49)[:6+i%7])
+		for j := range bet {
+			bet[j]++
+		}
+		bets[i] = bet
+	}
+	return bets
+}
+
+// BenchmarkMatchSlice matches bets by searching the winning numbers for each
+// of their numbers.
+func BenchmarkMatchSlice(b *testing.B) {
+	draw, _ := NewTotoDraw(WinningNumbers{3, 9, 28, 32, 37, 46}, 7)
+	bets := benchmarkBets()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, bet := range bets {
+			count := 0
+			additional := false
+			for _, n := range bet {
+				if draw.WinningNumbers.Contains(n) {
+					count++
+				} else if n == draw.AdditionalNumber {
+					additional = true
+				}
+			}
+			_, _ = count, additional
+		}
+	}
+}
+
+// BenchmarkMatchMask matches the same bets as masks against a DrawMask built
+// once, turning every bet into a mask as it is matched.
+func BenchmarkMatchMask(b *testing.B) {
+	draw, _ := NewTotoDraw(WinningNumbers{3, 9, 28, 32, 37, 46}, 7)
+	d, _ := NewDrawMask(draw)
+	bets := benchmarkBets()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, bet := range bets {
+			m, _ := bet.Mask()
+			_, _ = d.Match(m)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+)
+
+// lambdaOperation names what a Lambda invocation asks for, after the command
+// line commands: check, backtest, stats, probability, ev or tally. Payloads
+// without one are checks.
+type lambdaOperation struct {
+	Operation string `json:"operation"`
+}
+
+// LambdaTallyRequest is a tally on Lambda. The draw is given as in a check,
+// and the bets as text, one per line.
+type LambdaTallyRequest struct {
+	Request
+	BetLines string `json:"betLines"`
+}
+
+// lambdaEntry answers a Lambda invocation by its operation, with errors
+// reported to Lambda under their error code, so that callers see e.g.
+// "errorType": "INVALID_NUMBER".
+func lambdaEntry(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	res, err := lambdaRoute(ctx, payload)
+	if err != nil {
+		return nil, lambdaError(err)
+	}
+	return res, nil
+}
+
+func lambdaRoute(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var op lambdaOperation
+	if err := json.Unmarshal(payload, &op); err != nil {
+		return nil, decodeError(err, "error parsing request")
+	}
+
+	switch op.Operation {
+	case "", "check":
+		return invokeJSON(ctx, payload, lambdaHandler)
+	case "backtest":
+		return invokeJSON(ctx, payload, withContext(backtest))
+	case "stats":
+		return invokeJSON(ctx, payload, withContext(numberStats))
+	case "probability":
+		return invokeJSON(ctx, payload, withContext(odds))
+	case "ev":
+		return invokeJSON(ctx, payload, withContext(expectedValue))
+	case "tally":
+		return invokeJSON(ctx, payload, func(_ context.Context, request LambdaTallyRequest) (TallyResponse, error) {
+			return tally(request.Request, strings.NewReader(request.BetLines))
+		})
+	}
+
+	return nil, apperror.Errorf(apperror.InvalidRequest, "unknown operation: %s", op.Operation)
+}
+
+// invokeJSON decodes the payload into the request of f and answers it.
+func invokeJSON[Req any, Res any](ctx context.Context, payload json.RawMessage, f func(context.Context, Req) (Res, error)) (interface{}, error) {
+	var request Req
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, decodeError(err, "error parsing request")
+	}
+
+	res, err := f(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/stats"
+	"github.com/aws/aws-lambda-go/lambda/messages"
+)
+
+func TestLambdaOperations(t *testing.T) {
+	useBacktestDraws(t)
+
+	tests := []struct {
+		payload  string
+		expected interface{}
+	}{
+		{`{"winningNumbers": "1 2 3 4 5 6", "additionalNumber": "7", "bets": ["1 2 3 4 5 7"]}`, Response{}},
+		{`{"operation": "check", "drawNumber": 3912, "bets": ["1 2 3 4 5 7"]}`, Response{}},
+		{`{"operation": "backtest", "bets": ["1 2 3 4 5 7"]}`, BacktestResponse{}},
+		{`{"operation": "stats"}`, stats.Stats{}},
+		{`{"operation": "probability", "betType": "Ordinary"}`, ProbabilityResponse{}},
+		{`{"operation": "ev", "betType": "Ordinary"}`, ExpectedValueResponse{}},
+		{`{"operation": "tally", "drawNumber": 3912, "betLines": "1 2 3 4 5 6\n1 2 3 4 5 7\n"}`, TallyResponse{}},
+	}
+
+	for _, test := range tests {
+		res, err := lambdaEntry(context.Background(), json.RawMessage(test.payload))
+		if err != nil {
+			t.Errorf("expected error to be nil for %s got %v", test.payload, err)
+			continue
+		}
+
+		if got, want := fmt.Sprintf("%T", res), fmt.Sprintf("%T", test.expected); got != want {
+			t.Errorf("expected a %s for %s but got a %s", want, test.payload, got)
+		}
+	}
+
+	res, err := lambdaEntry(context.Background(), json.RawMessage(`{"operation": "tally", "drawNumber": 3912, "betLines": "1 2 3 4 5 6\n1 2 3 4 5 7\n"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tally := res.(TallyResponse); tally.Bets != 2 || tally.Winners.Group1 != 1 || tally.Winners.Group2 != 1 {
+		t.Errorf("expected a Group 1 and a Group 2 winner but got %+v", tally)
+	}
+}
+
+func TestLambdaUnknownOperation(t *testing.T) {
+	_, err := lambdaEntry(context.Background(), json.RawMessage(`{"operation": "draw"}`))
+
+	lambdaErr, ok := err.(messages.InvokeResponse_Error)
+	if !ok || lambdaErr.Type != "INVALID_REQUEST" {
+		t.Errorf("expected an INVALID_REQUEST lambda error but got %v", err)
+	}
+}
@@ -1,83 +1,238 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
 	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/ruleset"
 	"github.com/aikchun/totoprizecheck/internal/stringutils"
+	"github.com/aikchun/totoprizecheck/internal/ticket"
 	"github.com/aikchun/totoprizecheck/internal/totodraw"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/joho/godotenv"
 )
 
 type Request struct {
-	WinningNumbers   string   `json:"winningNumbers"`
-	AdditionalNumber string   `json:"additionalNumber"`
-	Bets             []string `json:"bets"`
+	WinningNumbers        NumbersInput            `json:"winningNumbers"`
+	AdditionalNumber      NumbersInput            `json:"additionalNumber"`
+	GroupPrizes           *prizetable.GroupPrizes `json:"groupPrizes,omitempty"`
+	PrizeStructure        string                  `json:"prizeStructure,omitempty"`
+	DrawNumber            int                     `json:"drawNumber,omitempty"`
+	DrawDate              string                  `json:"drawDate,omitempty"`
+	DrawCount             int                     `json:"drawCount,omitempty"`
+	Bets                  []NumbersInput          `json:"bets"`
+	TicketText            string                  `json:"ticketText,omitempty"`
+	Breakdown             bool                    `json:"breakdown"`
+	BreakdownCombinations bool                    `json:"breakdownCombinations"`
+	Strict                bool                    `json:"strict"`
+}
+
+// NumbersInput is a list of numbers given either as a string, "3 9 28", or as
+// a JSON array of integers, [3, 9, 28]. A single number may also be given as
+// a JSON integer. Arrays are kept as the JSON the request gave and validated
+// when parsed, so that a bad array bet is reported like a bad string bet.
+type NumbersInput string
+
+func (n *NumbersInput) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*n = NumbersInput(s)
+		return nil
+	}
+
+	var number int
+	if err := json.Unmarshal(b, &number); err == nil {
+		if number < 1 {
+			return apperror.Errorf(apperror.InvalidNumber, "number not within range: %d", number)
+		}
+		*n = NumbersInput(strconv.Itoa(number))
+		return nil
+	}
+
+	var numbers []int
+	if err := json.Unmarshal(b, &numbers); err != nil {
+		return fmt.Errorf("numbers should be a string or an array of integers")
+	}
+
+	*n = NumbersInput(b)
+	return nil
+}
+
+// BetError reports a bet of the request that could not be checked. Column
+// points at the offending number of a string, and NumberIndex at the one of
+// an array.
+type BetError struct {
+	Index       int    `json:"index"`
+	Input       string `json:"input"`
+	Reason      string `json:"reason"`
+	Column      int    `json:"column,omitempty"`
+	NumberIndex *int   `json:"numberIndex,omitempty"`
+}
+
+// newBetError reports the bet at index that failed to parse with err.
+func newBetError(index int, input string, err error) BetError {
+	betError := BetError{
+		Index:  index,
+		Input:  input,
+		Reason: err.Error(),
+		Column: stringutils.ColumnOf(err),
+	}
+	if i, ok := stringutils.IndexOf(err); ok {
+		betError.NumberIndex = &i
+	}
+	return betError
+}
+
+type checkOptions struct {
+	Breakdown             bool
+	BreakdownCombinations bool
+	MaxNumber             int
 }
 
-type ErrorResponseBody struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+// maxNumber returns the highest number of the game, which System Roll bets
+// roll up to.
+func (o checkOptions) maxNumber() int {
+	if o.MaxNumber == 0 {
+		return stringutils.MaxNumber
+	}
+	return o.MaxNumber
 }
 
+// defaultUnitPriceCents is the price of one ordinary combination.
+const defaultUnitPriceCents = 100
+
+// unitPriceCents is the price of one ordinary combination, which bets cost
+// for every combination they expand to. It is set from UNIT_BET_PRICE_CENTS.
+var unitPriceCents = defaultUnitPriceCents
+
+// checkWorkers is how many goroutines check the bets of a request. It is set
+// from CHECK_WORKERS and defaults to one for every CPU.
+var checkWorkers = runtime.GOMAXPROCS(0)
+
+// defaultMaxCheckCombinations is the most combinations a request checks,
+// enough for a breakdown of ten thousand System 12 bets.
+const defaultMaxCheckCombinations = 10_000_000
+
+// maxCheckCombinations is the most combinations a request checks over all its
+// draws, each bet counting once or, with a breakdown, once for every ordinary
+// combination. It is set from MAX_CHECK_COMBINATIONS.
+var maxCheckCombinations = defaultMaxCheckCombinations
+
+// maxDrawCount is the most draws a multi-draw check covers, about a year of
+// draws.
+const maxDrawCount = 104
+
+// drawStore holds the results of past draws, looked up when a request gives a
+// drawNumber or drawDate instead of the winning numbers. It is empty unless
+// DRAW_STORE_FILE is set.
+var drawStore drawstore.Store = &drawstore.Memory{}
+
+// now returns the current time, which decides the draws yet to be held.
+var now = time.Now
+
 type Response struct {
-	TotoDraw totodraw.TotoDraw    `json:"totoDraw"`
-	Results  []totodraw.BetResult `json:"results"`
+	DrawNumber              int                     `json:"drawNumber,omitempty"`
+	DrawDate                string                  `json:"drawDate,omitempty"`
+	Pending                 bool                    `json:"pending,omitempty"`
+	Ruleset                 string                  `json:"ruleset,omitempty"`
+	TotoDraw                *totodraw.TotoDraw      `json:"totoDraw,omitempty"`
+	Results                 []totodraw.BetResult    `json:"results"`
+	TotalPayoutCents        *int                    `json:"totalPayoutCents,omitempty"`
+	CostCents               int                     `json:"costCents"`
+	FixedGroups             *prizetable.FixedGroups `json:"fixedGroups,omitempty"`
+	Errors                  []BetError              `json:"errors,omitempty"`
+	Ticket                  *ticket.Ticket          `json:"ticket,omitempty"`
+	Draws                   []Response              `json:"draws,omitempty"`
+	DrawsWithoutGroupPrizes int                     `json:"drawsWithoutGroupPrizes,omitempty"`
 }
 
-func newTotoDraw(numbers string, a string) (totodraw.TotoDraw, error) {
+func newTotoDraw(numbers string, a string, rules ruleset.Ruleset) (totodraw.TotoDraw, error) {
 	var totoDraw totodraw.TotoDraw
-	sortedNumbers, err := stringutils.ConvertStringToUniqueSortedNumbers(numbers)
+	sortedNumbers, err := parseNumbers(numbers, rules)
 	if err != nil {
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: err.Error(),
-		}
-
-		return totoDraw, writeError(errorResponseBody)
+		return totoDraw, err
 	}
 
 	if len(sortedNumbers) != 6 {
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: "winning numbers should only contain 6 numbers",
-		}
-		return totoDraw, writeError(errorResponseBody)
+		return totoDraw, apperror.New(apperror.BadWinningNumbers, "winning numbers should only contain 6 numbers")
 	}
 
-	addNum, err := stringutils.ConvertStringToNumber(a)
+	addNum, err := stringutils.ConvertStringToNumberInRange(a, rules.MinNumber, rules.MaxNumber)
 	if err != nil {
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: "unable to parse additional number",
-		}
-
-		return totoDraw, writeError(errorResponseBody)
+		return totoDraw, apperror.New(apperror.InvalidNumber, "unable to parse additional number")
 	}
 
 	d, err := totodraw.NewTotoDraw(sortedNumbers, addNum)
 	if err != nil {
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: err.Error(),
-		}
-
-		return totoDraw, writeError(errorResponseBody)
+		return totoDraw, err
 	}
 
+	d.PrizeStructure = rules.PrizeStructure
+
 	return d, err
 }
 
-func mapBetStringsToBets(betStrings []string) ([]totodraw.Bet, error) {
+// rulesetForRequest returns the ruleset in force on the request's draw date,
+// or for its draw number when it has no draw date, or the latest ruleset when
+// it has neither.
+func rulesetForRequest(request Request) (ruleset.Ruleset, error) {
+	if request.DrawDate == "" && request.DrawNumber != 0 {
+		return ruleset.Default.ForDrawNumber(request.DrawNumber)
+	}
+
+	if request.DrawDate == "" {
+		return ruleset.Default.Latest(), nil
+	}
+
+	d, err := time.Parse("2006-01-02", request.DrawDate)
+	if err != nil {
+		return ruleset.Ruleset{}, apperror.New(apperror.InvalidDrawDate, "unable to parse draw date")
+	}
+
+	return ruleset.Default.ForDate(d)
+}
+
+// storedDraw looks up the draw of a request that gives a draw number or date
+// but no winning numbers. It returns nil when there is nothing to look up.
+func storedDraw(request Request) (*drawstore.Draw, error) {
+	if request.WinningNumbers != "" || request.DrawNumber == 0 && request.DrawDate == "" {
+		return nil, nil
+	}
+
+	var d drawstore.Draw
+	var err error
+	if request.DrawNumber != 0 {
+		d, err = drawStore.ByNumber(request.DrawNumber)
+	} else {
+		d, err = drawStore.ByDate(request.DrawDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.DrawDate != "" && request.DrawDate != d.DrawDate {
+		return nil, apperror.Errorf(apperror.InvalidDrawDate, "draw %d was held on %s, not %s", d.DrawNumber, d.DrawDate, request.DrawDate)
+	}
+	return &d, nil
+}
+
+func mapBetStringsToBets(betStrings []NumbersInput, rules ruleset.Ruleset) ([]totodraw.Bet, error) {
 	bets := make([]totodraw.Bet, len(betStrings))
 
 	for i, b := range betStrings {
-		bet, err := stringutils.ConvertStringToUniqueSortedNumbers(b)
+		bet, err := parseBet(string(b), rules)
 		if err != nil {
 			return []totodraw.Bet{}, err
 		}
@@ -88,7 +243,157 @@ func mapBetStringsToBets(betStrings []string) ([]totodraw.Bet, error) {
 	return bets, nil
 }
 
-func matchTotoDrawWithBet(t totodraw.TotoDraw, bet totodraw.Bet) totodraw.BetResult {
+// mapValidBetStringsToBets parses every bet on its own. Bets that fail to
+// parse are left nil and reported in the returned errors.
+func mapValidBetStringsToBets(betStrings []NumbersInput, rules ruleset.Ruleset) ([]totodraw.Bet, []BetError) {
+	bets := make([]totodraw.Bet, len(betStrings))
+	var betErrors []BetError
+
+	for i, b := range betStrings {
+		bet, err := parseBet(string(b), rules)
+		if err != nil {
+			betErrors = append(betErrors, newBetError(i, string(b), err))
+			continue
+		}
+
+		bets[i] = bet
+	}
+	return bets, betErrors
+}
+
+// ticketBoardInput is the text of a ticket board as reported in a BetError.
+func ticketBoardInput(b ticket.Board) string {
+	texts := make([]string, len(b.Numbers))
+	for i, number := range b.Numbers {
+		texts[i] = strconv.Itoa(number)
+	}
+
+	input := b.Label + ". " + strings.Join(texts, " ")
+	if len(b.Bet) > len(b.Numbers) {
+		input += " R"
+	}
+	return input
+}
+
+// validateTicketBoard checks a board against the rules of the draw. Its
+// numbers are checked as printed, before a System Roll marker is added.
+func validateTicketBoard(b ticket.Board, rules ruleset.Ruleset) error {
+	if err := rules.ValidateNumbers(b.Numbers); err != nil {
+		return fmt.Errorf("invalid board %s: %w", b.Label, err)
+	}
+
+	if err := rules.ValidateBet(b.Bet); err != nil {
+		return fmt.Errorf("invalid board %s: %w", b.Label, err)
+	}
+	return nil
+}
+
+// parseNumbers reads numbers given as a string or, when they start with "[",
+// as a JSON array of integers, and checks them against the rules.
+func parseNumbers(numbers string, rules ruleset.Ruleset) ([]int, error) {
+	if !strings.HasPrefix(strings.TrimSpace(numbers), "[") {
+		return stringutils.ConvertStringToUniqueSortedNumbersInRange(numbers, rules.MinNumber, rules.MaxNumber)
+	}
+
+	var array []int
+	if err := json.Unmarshal([]byte(numbers), &array); err != nil {
+		return nil, apperror.Errorf(apperror.InvalidNumber, "numbers should be an array of integers: %s", numbers)
+	}
+	return stringutils.UniqueSortedNumbersInRange(array, rules.MinNumber, rules.MaxNumber)
+}
+
+func parseBet(b string, rules ruleset.Ruleset) (totodraw.Bet, error) {
+	numbers, isSystemRoll := splitSystemRoll(b)
+
+	bet, err := parseNumbers(numbers, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSystemRoll {
+		bet = append([]int{totodraw.RollNumber}, bet...)
+	}
+
+	if err := rules.ValidateBet(bet); err != nil {
+		return nil, fmt.Errorf("invalid bet %q: %w", b, err)
+	}
+
+	return bet, nil
+}
+
+// splitSystemRoll strips the trailing "R" that marks a System Roll bet, as in
+// "1 2 3 4 5 R".
+func splitSystemRoll(b string) (string, bool) {
+	tokens := stringutils.Tokenize(b)
+	if len(tokens) < 2 {
+		return b, false
+	}
+
+	last := tokens[len(tokens)-1]
+	if last.Text != "R" && last.Text != "r" {
+		return b, false
+	}
+
+	return string([]rune(b)[:last.Column-1]), true
+}
+
+// maskedDraw is a draw with its numbers as bitmasks, built once to match many
+// bets against it.
+type maskedDraw struct {
+	totodraw.TotoDraw
+	mask   totodraw.DrawMask
+	masked bool
+}
+
+func newMaskedDraw(t totodraw.TotoDraw) maskedDraw {
+	m, err := totodraw.NewDrawMask(t)
+	return maskedDraw{TotoDraw: t, mask: m, masked: err == nil}
+}
+
+func matchTotoDrawWithBet(t maskedDraw, bet totodraw.Bet, opts checkOptions) totodraw.BetResult {
+	count, matchedAdditionalNumber := matchNumbers(t, bet)
+
+	betType := bet.GetBetType()
+	table := prizeTable(t.TotoDraw)
+
+	betResult := totodraw.BetResult{
+		Numbers:             bet.Numbers(),
+		BetType:             betType,
+		CostCents:           bet.CostCents(opts.maxNumber(), unitPriceCents),
+		NumbersMatched:      count,
+		HasAdditionalNumber: matchedAdditionalNumber,
+		Prize:               table.GetPrize(betType, count, matchedAdditionalNumber),
+		PrizeDetail:         table.GetPrizeDetail(betType, count, matchedAdditionalNumber),
+	}
+
+	if bet.IsSystemRoll() {
+		betResult.Prize = table.GetRollPrize(opts.maxNumber(), count, matchedAdditionalNumber)
+		betResult.PrizeDetail = table.DeriveRollPrize(opts.maxNumber(), count, matchedAdditionalNumber)
+	}
+
+	if t.GroupPrizes != nil {
+		payout := betResult.PrizeDetail.PayoutCents(*t.GroupPrizes)
+		betResult.PayoutCents = &payout
+	}
+
+	if opts.Breakdown {
+		betResult.Breakdown = breakdownTotoDrawPrize(t, bet, opts)
+	}
+
+	return betResult
+}
+
+// matchNumbers returns how many winning numbers the bet matched and whether
+// it matched the additional number. Bets and draws are matched as bitmasks,
+// falling back to searching the winning numbers for numbers a mask cannot
+// hold.
+func matchNumbers(t maskedDraw, bet totodraw.Bet) (int, bool) {
+	if t.masked {
+		if b, err := bet.Mask(); err == nil {
+			return t.mask.Match(b)
+		}
+	}
+
 	count := 0
 	matchedAdditionalNumber := false
 	for _, n := range bet {
@@ -105,96 +410,461 @@ func matchTotoDrawWithBet(t totodraw.TotoDraw, bet totodraw.Bet) totodraw.BetRes
 			matchedAdditionalNumber = true
 		}
 	}
+	return count, matchedAdditionalNumber
+}
 
-	betType := bet.GetBetType()
+// prizeTable returns the prize table of the draw's prize structure, falling
+// back to the current structure.
+func prizeTable(t totodraw.TotoDraw) *prizetable.Table {
+	if table, ok := prizetable.Structure(t.PrizeStructure); ok {
+		return table
+	}
 
-	return totodraw.BetResult{
-		Numbers:             bet,
-		BetType:             betType,
-		NumbersMatched:      count,
-		HasAdditionalNumber: matchedAdditionalNumber,
-		Prize:               prizetable.GetPrize(betType, count, matchedAdditionalNumber),
+	table, _ := prizetable.Structure(prizetable.Current)
+	return table
+}
+
+// breakdownTotoDrawPrize groups the winning ordinary combinations of the bet
+// by the prize they won, best prize first.
+func breakdownTotoDrawPrize(t maskedDraw, bet totodraw.Bet, opts checkOptions) []totodraw.PrizeTier {
+	type tierKey struct {
+		numbersMatched      int
+		hasAdditionalNumber bool
+	}
+
+	tiers := []totodraw.PrizeTier{}
+	indexes := make(map[tierKey]int)
+
+	for _, c := range checkOrdinaryBets(t, bet, opts) {
+		b, r := c.bet, c.result
+		if !r.PrizeDetail.Won {
+			continue
+		}
+
+		key := tierKey{r.NumbersMatched, r.HasAdditionalNumber}
+		i, ok := indexes[key]
+		if !ok {
+			i = len(tiers)
+			indexes[key] = i
+			tiers = append(tiers, totodraw.PrizeTier{
+				NumbersMatched:      r.NumbersMatched,
+				HasAdditionalNumber: r.HasAdditionalNumber,
+				Prize:               r.Prize,
+			})
+		}
+
+		tiers[i].Count += 1
+		if opts.BreakdownCombinations {
+			tiers[i].Combinations = append(tiers[i].Combinations, b)
+		}
 	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		if tiers[i].NumbersMatched != tiers[j].NumbersMatched {
+			return tiers[i].NumbersMatched > tiers[j].NumbersMatched
+		}
+		return tiers[i].HasAdditionalNumber && !tiers[j].HasAdditionalNumber
+	})
+
+	return tiers
 }
 
-func handler(w http.ResponseWriter, r *http.Request) {
-	defer r.Body.Close()
+// ordinaryResult is an ordinary combination of a bet with its result.
+type ordinaryResult struct {
+	bet    totodraw.Bet
+	result totodraw.BetResult
+}
 
-	m := r.Method
+// checkOrdinaryBets expands the bet into its ordinary combinations, C(n, 6)
+// for a bet of n numbers, and checks each of them on its own against the
+// draw.
+func checkOrdinaryBets(t maskedDraw, bet totodraw.Bet, opts checkOptions) []ordinaryResult {
+	bets := bet.OrdinaryBets(opts.maxNumber())
 
-	if m != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		w.Write([]byte(""))
-		return
+	results := make([]ordinaryResult, len(bets))
+	for i, b := range bets {
+		results[i] = ordinaryResult{b, matchTotoDrawWithBet(t, b, checkOptions{})}
 	}
+	return results
+}
 
-	var request Request
+// deriveTotoDrawPrize adds up the prizes of the ordinary combinations of the
+// bet, which is what the prize table's System and System Roll prizes stand
+// for.
+func deriveTotoDrawPrize(t maskedDraw, bet totodraw.Bet, opts checkOptions) prizetable.Prize {
+	var p prizetable.Prize
+	for _, c := range checkOrdinaryBets(t, bet, opts) {
+		p = p.Add(c.result.PrizeDetail)
+	}
+	return p
+}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadGateway)
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: "error parsing request body",
+// handler serves checks.
+var handler = jsonContextHandler(lambdaHandler)
+
+// jsonHandler serves POST requests whose JSON body is answered by f.
+func jsonHandler[Req any, Res any](f func(Req) (Res, error)) http.HandlerFunc {
+	return jsonContextHandler(withContext(f))
+}
+
+// withContext adapts f, which runs to completion, to take a context.
+func withContext[Req any, Res any](f func(Req) (Res, error)) func(context.Context, Req) (Res, error) {
+	return func(_ context.Context, request Req) (Res, error) {
+		return f(request)
+	}
+}
+
+// jsonContextHandler is jsonHandler for an f that stops once the HTTP request
+// is cancelled.
+func jsonContextHandler[Req any, Res any](f func(context.Context, Req) (Res, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			writeErrorHttp(w, apperror.New(apperror.MethodNotAllowed, "method not allowed"))
+			return
 		}
-		json.NewEncoder(w).Encode(errorResponseBody)
-		return
+
+		var request Req
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeErrorHttp(w, decodeError(err, "error parsing request body"))
+			return
+		}
+
+		res, err := f(r.Context(), request)
+		if err != nil {
+			writeErrorHttp(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func lambdaHandler(ctx context.Context, request Request) (Response, error) {
+	var t *ticket.Ticket
+
+	if request.TicketText != "" {
+		parsed, err := ticket.Parse(request.TicketText)
+		if err != nil {
+			return Response{}, err
+		}
+
+		if request.DrawNumber == 0 {
+			request.DrawNumber = parsed.DrawNumber
+		}
+
+		if request.DrawDate == "" {
+			request.DrawDate = parsed.DrawDate
+		}
+
+		// Winning numbers are for a single draw, so the other draws of the
+		// ticket are only checked against stored results.
+		if request.DrawCount == 0 && request.WinningNumbers == "" {
+			request.DrawCount = parsed.DrawCount
+		}
+		t = &parsed
+	}
+
+	if request.DrawCount < 0 {
+		return Response{}, apperror.Errorf(apperror.InvalidRequest, "number of draws should not be negative, got %d", request.DrawCount)
+	}
+
+	if request.DrawCount > 1 {
+		return checkDraws(ctx, request, t)
+	}
+
+	return checkDraw(ctx, request, t)
+}
+
+// checkDraws checks the bets of a multi-draw ticket against each of the
+// request.DrawCount draws from the starting draw. Draws without stored
+// results are reported as pending. The total payout is left out when a held
+// draw has no group prizes, as the ticket's winnings are then unknown.
+func checkDraws(ctx context.Context, request Request, t *ticket.Ticket) (Response, error) {
+	if request.DrawCount > maxDrawCount {
+		return Response{}, apperror.Errorf(apperror.InvalidRequest, "tickets cover at most %d draws, got %d", maxDrawCount, request.DrawCount)
+	}
+
+	if request.WinningNumbers != "" {
+		return Response{}, apperror.New(apperror.InvalidRequest, "winning numbers cannot be given for more than one draw")
 	}
 
-	res, err := lambdaHandler(request)
+	if request.GroupPrizes != nil {
+		return Response{}, apperror.New(apperror.InvalidRequest, "group prizes cannot be given for more than one draw")
+	}
+
+	start := request.DrawNumber
+	if start == 0 && request.DrawDate != "" {
+		d, err := drawStore.ByDate(request.DrawDate)
+		if err != nil {
+			return Response{}, err
+		}
+		start = d.DrawNumber
+	}
+
+	if start == 0 {
+		return Response{}, apperror.New(apperror.InvalidRequest, "checking more than one draw needs the starting drawNumber")
+	}
+
+	response := Response{
+		DrawNumber: start,
+		DrawDate:   request.DrawDate,
+		Results:    []totodraw.BetResult{},
+		Ticket:     t,
+	}
+	totalPayout, held := 0, 0
+
+	for n := start; n < start+request.DrawCount; n++ {
+		r := request
+		r.DrawNumber = n
+		r.DrawDate = ""
+
+		drawResponse, err := checkDraw(ctx, r, t)
+		if apperror.CodeOf(err) == apperror.DrawNotFound {
+			pending, pendingErr := drawPending(n)
+			if pendingErr != nil {
+				return Response{}, pendingErr
+			}
+			if !pending {
+				return Response{}, err
+			}
+
+			pendingDraw := Response{DrawNumber: n, Pending: true, Results: []totodraw.BetResult{}}
+			pendingDraw.CostCents = pendingCostCents(request, t)
+			response.CostCents += pendingDraw.CostCents
+			response.Draws = append(response.Draws, pendingDraw)
+			continue
+		}
+		if err != nil {
+			return Response{}, fmt.Errorf("draw %d: %w", n, err)
+		}
+
+		drawResponse.Ticket = nil
+		held++
+		response.CostCents += drawResponse.CostCents
+		if drawResponse.TotalPayoutCents != nil {
+			totalPayout += *drawResponse.TotalPayoutCents
+		} else {
+			response.DrawsWithoutGroupPrizes++
+		}
+		response.Draws = append(response.Draws, drawResponse)
+	}
+
+	if held > 0 && response.DrawsWithoutGroupPrizes == 0 {
+		response.TotalPayoutCents = &totalPayout
+	}
+	return response, nil
+}
+
+// drawPending reports whether a draw missing from the store is yet to be
+// held. Regular draws are held every Monday and Thursday, so draws after the
+// latest stored draw are dated by counting those days from it. Draws before
+// it, or when the store is empty, are past draws whose results are missing.
+func drawPending(drawNumber int) (bool, error) {
+	draws, err := drawStore.Draws()
+	if err != nil || len(draws) == 0 {
+		return false, err
+	}
+
+	latest := draws[len(draws)-1]
+	if drawNumber <= latest.DrawNumber {
+		return false, nil
+	}
+
+	date, err := time.Parse(drawstore.DateLayout, latest.DrawDate)
 	if err != nil {
-		writeErrorHttp(w, err)
-		return
+		return false, err
+	}
+
+	for n := latest.DrawNumber; n < drawNumber; n++ {
+		date = date.AddDate(0, 0, 1)
+		for date.Weekday() != time.Monday && date.Weekday() != time.Thursday {
+			date = date.AddDate(0, 0, 1)
+		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(res)
+	y, m, d := now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !date.Before(today), nil
 }
 
-func writeErrorHttp(w http.ResponseWriter, err error) {
-	var errorResponseBody ErrorResponseBody
-	err = json.Unmarshal([]byte(fmt.Sprint(err)), &errorResponseBody)
-	if errorResponseBody.Status == http.StatusBadRequest {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponseBody)
+// pendingCostCents returns the cost of the valid bets of a request, and the
+// boards of its ticket, in a draw yet to be held.
+func pendingCostCents(request Request, t *ticket.Ticket) int {
+	rules := ruleset.Default.Latest()
+
+	cost := 0
+	for _, bet := range validRequestBets(request, t, rules) {
+		cost += bet.CostCents(rules.MaxNumber, unitPriceCents)
 	}
+	return cost
 }
 
-func writeError(e ErrorResponseBody) error {
-	eByte, _ := json.Marshal(e)
-	return fmt.Errorf(string(eByte))
+// validRequestBets returns the valid bets of a request, followed by the valid
+// boards of its ticket.
+func validRequestBets(request Request, t *ticket.Ticket, rules ruleset.Ruleset) []totodraw.Bet {
+	parsed, _ := mapValidBetStringsToBets(request.Bets, rules)
+
+	var bets []totodraw.Bet
+	for _, bet := range parsed {
+		if bet != nil {
+			bets = append(bets, bet)
+		}
+	}
+
+	if t != nil {
+		for _, b := range t.Boards {
+			if validateTicketBoard(b, rules) == nil {
+				bets = append(bets, b.Bet)
+			}
+		}
+	}
+	return bets
 }
 
-func lambdaHandler(request Request) (Response, error) {
-	var response Response
+// drawForRequest returns the draw of a request, from its winning numbers or
+// from the draw store, and the rules in force for it. The returned request
+// carries the number and date of a stored draw.
+func drawForRequest(request Request) (totodraw.TotoDraw, ruleset.Ruleset, Request, error) {
+	var draw totodraw.TotoDraw
 
-	draw, err := newTotoDraw(request.WinningNumbers, request.AdditionalNumber)
+	stored, err := storedDraw(request)
 	if err != nil {
-		return response, err
+		return draw, ruleset.Ruleset{}, request, err
+	}
+
+	if stored != nil {
+		request.DrawNumber = stored.DrawNumber
+		request.DrawDate = stored.DrawDate
+	}
+
+	rules, err := rulesetForRequest(request)
+	if err != nil {
+		return draw, rules, request, err
+	}
+
+	if stored != nil {
+		draw = stored.TotoDraw
+		draw.PrizeStructure = rules.PrizeStructure
+	} else {
+		draw, err = newTotoDraw(string(request.WinningNumbers), string(request.AdditionalNumber), rules)
+		if err != nil {
+			return draw, rules, request, err
+		}
+	}
+
+	if request.GroupPrizes != nil {
+		if !request.GroupPrizes.IsValid() {
+			return draw, rules, request, apperror.New(apperror.InvalidGroupPrizes, "group prizes should not be negative")
+		}
+
+		draw.GroupPrizes = request.GroupPrizes
+	}
+
+	if request.PrizeStructure != "" {
+		if _, ok := prizetable.Structure(request.PrizeStructure); !ok {
+			return draw, rules, request, apperror.Errorf(apperror.UnknownPrizeStructure, "unknown prize structure: %s", request.PrizeStructure)
+		}
+
+		draw.PrizeStructure = request.PrizeStructure
 	}
 
-	bets, err := mapBetStringsToBets(request.Bets)
+	return draw, rules, request, nil
+}
+
+// checkDraw checks the bets of a request, and the boards of its ticket,
+// against a single draw.
+func checkDraw(ctx context.Context, request Request, t *ticket.Ticket) (Response, error) {
+	response := Response{Ticket: t}
+
+	draw, rules, request, err := drawForRequest(request)
 	if err != nil {
-		errorResponseBody := ErrorResponseBody{
-			Status:  400,
-			Message: err.Error(),
+		return response, err
+	}
+
+	var bets []totodraw.Bet
+	if request.Strict {
+		bets, err = mapBetStringsToBets(request.Bets, rules)
+		if err != nil {
+			return response, err
+		}
+	} else {
+		bets, response.Errors = mapValidBetStringsToBets(request.Bets, rules)
+	}
+
+	// Ticket boards are checked after the bets of the request and numbered
+	// after them.
+	labels := make([]string, len(bets))
+	if t != nil {
+		for _, b := range t.Boards {
+			if err := validateTicketBoard(b, rules); err != nil {
+				if request.Strict {
+					return Response{}, err
+				}
+
+				response.Errors = append(response.Errors, BetError{
+					Index:  len(bets),
+					Input:  ticketBoardInput(b),
+					Reason: err.Error(),
+				})
+				b.Bet = nil
+			}
+
+			bets = append(bets, b.Bet)
+			labels = append(labels, b.Label)
 		}
+	}
 
-		return response, writeError(errorResponseBody)
+	opts := checkOptions{
+		Breakdown:             request.Breakdown || request.BreakdownCombinations,
+		BreakdownCombinations: request.BreakdownCombinations,
+		MaxNumber:             rules.MaxNumber,
 	}
 
-	results := make([]totodraw.BetResult, len(bets))
+	// A multi-draw request checks the same bets in each of its draws.
+	if err := checkWorkLimit(bets, request.DrawCount, opts); err != nil {
+		return Response{}, err
+	}
 
-	for i, bet := range bets {
-		betResult := matchTotoDrawWithBet(draw, bet)
-		results[i] = betResult
+	results, err := checkBets(ctx, draw, bets, labels, opts)
+	if err != nil {
+		return Response{}, err
+	}
+
+	totalPayout := 0
+	for _, betResult := range results {
+		if betResult.PayoutCents != nil {
+			totalPayout += *betResult.PayoutCents
+		}
+		response.CostCents += betResult.CostCents
 	}
 
-	response.TotoDraw = draw
+	response.DrawNumber = request.DrawNumber
+	response.DrawDate = request.DrawDate
+	response.Ruleset = rules.Name
+	response.TotoDraw = &draw
 	response.Results = results
+	if draw.GroupPrizes != nil {
+		response.TotalPayoutCents = &totalPayout
+	}
+
+	if fixedGroups := prizeTable(draw).FixedGroups(); fixedGroups != (prizetable.FixedGroups{}) {
+		response.FixedGroups = &fixedGroups
+	}
 	return response, nil
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		loadPrizeTable()
+		loadDrawStore()
+		loadUnitPrice()
+		loadCheckLimits()
+		os.Exit(runCLI(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	err := godotenv.Load(".env")
 
 	e := os.Getenv("ENVIRONMENT")
@@ -206,14 +876,93 @@ func main() {
 		log.Printf("Couldn't find .env")
 	}
 
+	loadPrizeTable()
+	loadDrawStore()
+	loadUnitPrice()
+	loadCheckLimits()
+
 	if isRunningOnLambda {
-		lambda.Start(lambdaHandler)
+		lambda.Start(lambdaEntry)
 	} else {
 		p := ":8080"
 		http.HandleFunc("/", handler)
+		http.HandleFunc("/backtest", jsonHandler(backtest))
+		http.HandleFunc("/stats", jsonHandler(numberStats))
+		http.HandleFunc("/probability", jsonHandler(odds))
+		http.HandleFunc("/ev", jsonHandler(expectedValue))
+		http.HandleFunc("/tally", tallyHandler)
 		fmt.Printf("starting http server\n")
 		fmt.Printf("listening: %s\n", p)
 
 		log.Fatal(http.ListenAndServe(p, nil))
 	}
 }
+
+// loadPrizeTable replaces the embedded prize table with the one in
+// PRIZE_TABLE_FILE, if set.
+func loadPrizeTable() {
+	f := os.Getenv("PRIZE_TABLE_FILE")
+	if f == "" {
+		return
+	}
+
+	t, err := prizetable.LoadFile(f)
+	if err != nil {
+		log.Fatalf("unable to load prize table %s: %v", f, err)
+	}
+
+	if err := t.Verify(); err != nil {
+		log.Fatalf("prize table %s does not match its ordinary prizes: %v", f, err)
+	}
+	prizetable.Use(t)
+}
+
+// loadDrawStore opens the draw results in DRAW_STORE_FILE, if set.
+func loadDrawStore() {
+	f := os.Getenv("DRAW_STORE_FILE")
+	if f == "" {
+		return
+	}
+
+	s, err := drawstore.OpenFile(f)
+	if err != nil {
+		log.Fatalf("unable to open draw store %s: %v", f, err)
+	}
+	drawStore = s
+}
+
+// loadUnitPrice sets the price of one ordinary combination from
+// UNIT_BET_PRICE_CENTS, if set.
+func loadUnitPrice() {
+	p := os.Getenv("UNIT_BET_PRICE_CENTS")
+	if p == "" {
+		return
+	}
+
+	cents, err := strconv.Atoi(p)
+	if err != nil || cents < 0 {
+		log.Fatalf("invalid UNIT_BET_PRICE_CENTS %s", p)
+	}
+	unitPriceCents = cents
+}
+
+// loadCheckLimits sets the number of workers checking a request and the most
+// combinations a request checks from CHECK_WORKERS and
+// MAX_CHECK_COMBINATIONS, if set.
+func loadCheckLimits() {
+	if w := os.Getenv("CHECK_WORKERS"); w != "" {
+		workers, err := strconv.Atoi(w)
+		if err != nil || workers < 1 {
+			log.Fatalf("invalid CHECK_WORKERS %s", w)
+		}
+		checkWorkers = workers
+	}
+
+	if c := os.Getenv("MAX_CHECK_COMBINATIONS"); c != "" {
+		combinations, err := strconv.Atoi(c)
+		if err != nil || combinations < 1 {
+			log.Fatalf("invalid MAX_CHECK_COMBINATIONS %s", c)
+		}
+		maxCheckCombinations = combinations
+	}
+}
@@ -2,19 +2,30 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/drawstore"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/ruleset"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+	"github.com/aws/aws-lambda-go/lambda/messages"
 )
 
 func TestNewTotoDraw(t *testing.T) {
 	inputWinningNumbers := "7 13 18 19 25 29"
 	inputAdditionalNumber := "36"
 
-	totoDraw, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber)
+	totoDraw, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber, ruleset.Default.Latest())
 	if err != nil {
 		t.Errorf("error in NewTotoDraw %v", err)
 	}
@@ -37,39 +48,57 @@ func TestNewTotoDrawWrongWinningNumberLength(t *testing.T) {
 	inputWinningNumbers := "7 13 18 19 25 29 30"
 	inputAdditionalNumber := "36"
 
-	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber)
+	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber, ruleset.Default.Latest())
 
-	expectedErrorString := "{\"status\":400,\"message\":\"winning numbers should only contain 6 numbers\"}"
+	expectedErrorString := "winning numbers should only contain 6 numbers"
 	actualErrorString := fmt.Sprint(err)
 	if actualErrorString != expectedErrorString {
 		t.Errorf("expected '%s' but got: '%s' instead", expectedErrorString, actualErrorString)
 	}
+
+	expectedCode := apperror.BadWinningNumbers
+	actualCode := apperror.CodeOf(err)
+	if actualCode != expectedCode {
+		t.Errorf("expected code %s but got %s instead", expectedCode, actualCode)
+	}
 }
 
 func TestNewTotoDrawDuplicateWinningNumber(t *testing.T) {
 	inputWinningNumbers := "7 13 18 19 29 29"
 	inputAdditionalNumber := "36"
 
-	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber)
+	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber, ruleset.Default.Latest())
 
-	expectedErrorString := "{\"status\":400,\"message\":\"duplicate numbers found: [7 13 18 19 29 29]\"}"
+	expectedErrorString := "duplicate numbers found at column 15: [7 13 18 19 29 29]"
 	actualErrorString := fmt.Sprint(err)
 	if actualErrorString != expectedErrorString {
 		t.Errorf("expected '%s' but got: '%s' instead", expectedErrorString, actualErrorString)
 	}
+
+	expectedCode := apperror.DuplicateNumber
+	actualCode := apperror.CodeOf(err)
+	if actualCode != expectedCode {
+		t.Errorf("expected code %s but got %s instead", expectedCode, actualCode)
+	}
 }
 
 func TestNewTotoDrawDuplicateWinningNumberInAdditionalNumber(t *testing.T) {
 	inputWinningNumbers := "7 13 18 19 29 36"
 	inputAdditionalNumber := "36"
 
-	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber)
+	_, err := newTotoDraw(inputWinningNumbers, inputAdditionalNumber, ruleset.Default.Latest())
 
-	expectedErrorString := "{\"status\":400,\"message\":\"duplicate number found in additional number\"}"
+	expectedErrorString := "duplicate number found in additional number"
 	actualErrorString := fmt.Sprint(err)
 	if actualErrorString != expectedErrorString {
 		t.Errorf("expected '%s' but got: '%s' instead", expectedErrorString, actualErrorString)
 	}
+
+	expectedCode := apperror.DuplicateNumber
+	actualCode := apperror.CodeOf(err)
+	if actualCode != expectedCode {
+		t.Errorf("expected code %s but got %s instead", expectedCode, actualCode)
+	}
 }
 
 func TestEndpointNotAllowedMethod(t *testing.T) {
@@ -141,7 +170,7 @@ func TestEndpointInvalidCharactersInWinningNumber(t *testing.T) {
 		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	expectedMessage := fmt.Sprintf("failed to convert a7: [01 02 03 04 05 06 a7]")
+	expectedMessage := fmt.Sprintf("failed to convert a7 at column 19: [01 02 03 04 05 06 a7]")
 	actualMessage := errorResponseBody.Message
 
 	if actualMessage != expectedMessage {
@@ -339,3 +368,1194 @@ func TestResponseGroupTwoBet(t *testing.T) {
 		t.Errorf("expected matches: %t but got %t instead", expectedHasAdditionalNumber, actualHasAdditionalNumber)
 	}
 }
+
+func TestResponsePrizeDetail(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 7 8 9 10 11"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrize := "Group 4 + Group 5 ×6 + Group 6 ×24 + Group 7 ×16"
+	actualPrize := response.Results[0].Prize
+
+	if expectedPrize != actualPrize {
+		t.Errorf("expected prize: %s but got %s instead", expectedPrize, actualPrize)
+	}
+
+	expectedPrizeDetail := prizetable.Prize{Group4: 4, Group5: 6, Group6: 24, Group7: 16, FixedCents: 106000, Won: true}
+	actualPrizeDetail := response.Results[0].PrizeDetail
+
+	if expectedPrizeDetail != actualPrizeDetail {
+		t.Errorf("expected prize detail: %+v but got %+v instead", expectedPrizeDetail, actualPrizeDetail)
+	}
+}
+
+func TestDeriveTotoDrawPrize(t *testing.T) {
+	draw, err := newTotoDraw("3 9 28 32 37 46", "7", ruleset.Default.Latest())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	bets := []totodraw.Bet{
+		{3, 9, 28, 32, 37, 46, 7},
+		{3, 9, 28, 32, 7, 1, 2, 4},
+		{3, 9, 28, 7, 1, 2, 4, 5, 6},
+		{3, 9, 28, 32, 37, 1, 2, 4, 5, 6},
+		{3, 9, 28, 32, 37, 46, 7, 1, 2, 4, 5},
+		{3, 9, 28, 7, 1, 2, 4, 5, 6, 8, 10, 11},
+		{totodraw.RollNumber, 3, 9, 28, 32, 37},
+		{totodraw.RollNumber, 3, 9, 28, 7, 1},
+		{totodraw.RollNumber, 3, 1, 2, 4, 5},
+	}
+
+	for _, bet := range bets {
+		sort.Ints(bet)
+
+		expectedPrize := matchTotoDrawWithBet(newMaskedDraw(draw), bet, checkOptions{}).PrizeDetail
+		actualPrize := deriveTotoDrawPrize(newMaskedDraw(draw), bet, checkOptions{})
+
+		if expectedPrize != actualPrize {
+			t.Errorf("expected prize for %v: %+v but got %+v instead", bet, expectedPrize, actualPrize)
+		}
+	}
+}
+
+func TestResponseBreakdown(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 7 8 9 10 11"], "breakdown": true}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedBreakdown := []totodraw.PrizeTier{
+		{NumbersMatched: 4, HasAdditionalNumber: true, Prize: "Group 4", Count: 4},
+		{NumbersMatched: 4, HasAdditionalNumber: false, Prize: "Group 5", Count: 6},
+		{NumbersMatched: 3, HasAdditionalNumber: true, Prize: "Group 6", Count: 24},
+		{NumbersMatched: 3, HasAdditionalNumber: false, Prize: "Group 7", Count: 16},
+	}
+	actualBreakdown := response.Results[0].Breakdown
+
+	if len(expectedBreakdown) != len(actualBreakdown) {
+		t.Fatalf("expected breakdown: %+v but got %+v instead", expectedBreakdown, actualBreakdown)
+	}
+
+	for i, tier := range expectedBreakdown {
+		actualTier := actualBreakdown[i]
+		if tier.NumbersMatched != actualTier.NumbersMatched || tier.HasAdditionalNumber != actualTier.HasAdditionalNumber || tier.Prize != actualTier.Prize || tier.Count != actualTier.Count {
+			t.Errorf("expected tier: %+v but got %+v instead", tier, actualTier)
+		}
+
+		if actualTier.Combinations != nil {
+			t.Errorf("expected no combinations but got %v instead", actualTier.Combinations)
+		}
+	}
+}
+
+func TestMatchTotoDrawWithBetBreakdownCombinations(t *testing.T) {
+	draw, err := newTotoDraw("1 2 3 4 5 6", "7", ruleset.Default.Latest())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	betResult := matchTotoDrawWithBet(newMaskedDraw(draw), totodraw.Bet{1, 2, 3, 4, 5, 7, 8}, checkOptions{Breakdown: true, BreakdownCombinations: true})
+
+	expectedPrize := "Group 2"
+	expectedCombination := []int{1, 2, 3, 4, 5, 7}
+	firstTier := betResult.Breakdown[0]
+
+	if firstTier.Prize != expectedPrize || firstTier.Count != 1 || len(firstTier.Combinations) != 1 {
+		t.Fatalf("expected 1 combination winning %s but got %+v instead", expectedPrize, firstTier)
+	}
+
+	for i, n := range expectedCombination {
+		if firstTier.Combinations[0][i] != n {
+			t.Errorf("expected combination %v but got %v instead", expectedCombination, firstTier.Combinations[0])
+			break
+		}
+	}
+}
+
+func TestResponsePayout(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "groupPrizes": {"group1": 100000000, "group2": 5000000, "group3": 150000, "group4": 40000}, "bets": ["1 2 3 4 5 7", "1 2 3 10 11 12"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPayouts := []int{5000000, 1000}
+	for i, expectedPayout := range expectedPayouts {
+		actualPayout := response.Results[i].PayoutCents
+		if actualPayout == nil || *actualPayout != expectedPayout {
+			t.Errorf("expected payout: %d but got %v instead", expectedPayout, actualPayout)
+		}
+	}
+
+	expectedTotalPayout := 5001000
+	actualTotalPayout := response.TotalPayoutCents
+
+	if actualTotalPayout == nil || *actualTotalPayout != expectedTotalPayout {
+		t.Errorf("expected total payout: %d but got %v instead", expectedTotalPayout, actualTotalPayout)
+	}
+}
+
+func TestResponseWithoutGroupPrizes(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 7"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	if response.Results[0].PayoutCents != nil || response.TotalPayoutCents != nil {
+		t.Errorf("expected no payouts but got %v and %v instead", response.Results[0].PayoutCents, response.TotalPayoutCents)
+	}
+}
+
+func TestResponseCurrentPrizeStructure(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 10 11 12"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrizeDetail := prizetable.Prize{Group7: 1, FixedCents: 1000, Won: true}
+	actualPrizeDetail := response.Results[0].PrizeDetail
+
+	if expectedPrizeDetail != actualPrizeDetail {
+		t.Errorf("expected prize detail: %+v but got %+v instead", expectedPrizeDetail, actualPrizeDetail)
+	}
+
+	expectedFixedGroups := prizetable.FixedGroups{Group5: 5000, Group6: 2500, Group7: 1000}
+	actualFixedGroups := response.FixedGroups
+
+	if actualFixedGroups == nil || *actualFixedGroups != expectedFixedGroups {
+		t.Errorf("expected fixed groups: %+v but got %+v instead", expectedFixedGroups, actualFixedGroups)
+	}
+}
+
+func TestResponseLegacyPrizeStructure(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "prizeStructure": "legacy", "bets": ["1 2 3 10 11 12"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrizeDetail := prizetable.Prize{FixedCents: 1000, Won: true}
+	actualPrizeDetail := response.Results[0].PrizeDetail
+
+	if expectedPrizeDetail != actualPrizeDetail {
+		t.Errorf("expected prize detail: %+v but got %+v instead", expectedPrizeDetail, actualPrizeDetail)
+	}
+
+	if response.FixedGroups != nil {
+		t.Errorf("expected no fixed groups but got %+v instead", response.FixedGroups)
+	}
+}
+
+func TestEndpointUnknownPrizeStructure(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "prizeStructure": "future"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+}
+
+func TestResponseHistoricalRuleset(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "drawDate": "2010-01-04", "bets": ["1 2 3 10 11 45"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedRuleset := "6/45"
+	if response.Ruleset != expectedRuleset {
+		t.Errorf("expected ruleset: %s but got %s instead", expectedRuleset, response.Ruleset)
+	}
+
+	expectedPrizeDetail := prizetable.Prize{FixedCents: 1000, Won: true}
+	actualPrizeDetail := response.Results[0].PrizeDetail
+
+	if expectedPrizeDetail != actualPrizeDetail {
+		t.Errorf("expected prize detail: %+v but got %+v instead", expectedPrizeDetail, actualPrizeDetail)
+	}
+}
+
+func TestResponseRulesetByDrawNumber(t *testing.T) {
+	tests := []struct {
+		drawNumber int
+		expected   string
+	}{
+		{2500, "6/45"},
+		{3001, "6/49"},
+	}
+
+	for _, test := range tests {
+		request := Request{WinningNumbers: "01 02 03 04 05 06", AdditionalNumber: "07", DrawNumber: test.drawNumber, Bets: []NumbersInput{"1 2 3 10 11 45"}}
+
+		response, err := lambdaHandler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if response.Ruleset != test.expected {
+			t.Errorf("expected ruleset %s for draw %d but got %s instead", test.expected, test.drawNumber, response.Ruleset)
+		}
+	}
+}
+
+func TestEndpointNumberOutsideHistoricalRange(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 46", "additionalNumber": "07", "drawDate": "2010-01-04"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var errorResponseBody ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&errorResponseBody); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedMessage := "number not within range: 46 at column 16: [01 02 03 04 05 46]"
+	actualMessage := errorResponseBody.Message
+
+	if actualMessage != expectedMessage {
+		t.Errorf("expected message: %s got %s", expectedMessage, actualMessage)
+	}
+}
+
+func TestEndpointInvalidDrawDate(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "drawDate": "14/10/24"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+}
+
+func TestResponseSystemRoll(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 R"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedBetType := "System Roll"
+	actualBetType := response.Results[0].BetType
+
+	if expectedBetType != actualBetType {
+		t.Errorf("expected bet type: %s but got %s instead", expectedBetType, actualBetType)
+	}
+
+	expectedNumbers := []int{1, 2, 3, 4, 5}
+	actualNumbers := response.Results[0].Numbers
+
+	if len(expectedNumbers) != len(actualNumbers) {
+		t.Errorf("expected numbers: %v but got %v instead", expectedNumbers, actualNumbers)
+	}
+
+	expectedPrize := "Group 1 + 2 + 3"
+	actualPrize := response.Results[0].Prize
+
+	if expectedPrize != actualPrize {
+		t.Errorf("expected prize: %s but got %s instead", expectedPrize, actualPrize)
+	}
+
+	expectedPrizeDetail := prizetable.Prize{Group1: 1, Group2: 1, Group3: 42, Won: true}
+	actualPrizeDetail := response.Results[0].PrizeDetail
+
+	if expectedPrizeDetail != actualPrizeDetail {
+		t.Errorf("expected prize detail: %+v but got %+v instead", expectedPrizeDetail, actualPrizeDetail)
+	}
+}
+
+func TestEndpointSystemRollWrongSize(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 R"], "strict": true}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+}
+
+func TestEndpointInvalidBetSize(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 6", "1 2 3 4 5"], "strict": true}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var errorResponseBody ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&errorResponseBody); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedMessage := `invalid bet "1 2 3 4 5": bets should contain 6 to 12 numbers, got 5`
+	actualMessage := errorResponseBody.Message
+
+	if actualMessage != expectedMessage {
+		t.Errorf("expected message: %s got %s", expectedMessage, actualMessage)
+	}
+}
+
+func TestResponseNoPrize(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 10 11 12 13"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrize := "no prize"
+	actualPrize := response.Results[0].Prize
+
+	if expectedPrize != actualPrize {
+		t.Errorf("expected prize: %s but got %s instead", expectedPrize, actualPrize)
+	}
+}
+
+func TestResponsePartialSuccess(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 6", "1 2 3 4 5", "1 2 3 4 5 7", "1 2 3 a4 5 7"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusOK
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedIndexes := []int{0, 2}
+	if len(response.Results) != len(expectedIndexes) {
+		t.Fatalf("expected %d results but got %d instead", len(expectedIndexes), len(response.Results))
+	}
+
+	for i, index := range expectedIndexes {
+		if response.Results[i].Index != index {
+			t.Errorf("expected result index: %d but got %d instead", index, response.Results[i].Index)
+		}
+	}
+
+	expectedErrors := []BetError{
+		{Index: 1, Input: "1 2 3 4 5", Reason: `invalid bet "1 2 3 4 5": bets should contain 6 to 12 numbers, got 5`},
+		{Index: 3, Input: "1 2 3 a4 5 7", Reason: "failed to convert a4 at column 7: [1 2 3 a4 5 7]", Column: 7},
+	}
+	if len(response.Errors) != len(expectedErrors) {
+		t.Fatalf("expected errors: %+v but got %+v instead", expectedErrors, response.Errors)
+	}
+
+	for i, expectedError := range expectedErrors {
+		if response.Errors[i] != expectedError {
+			t.Errorf("expected error: %+v but got %+v instead", expectedError, response.Errors[i])
+		}
+	}
+}
+
+func TestEndpointMalformedRequestBody(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": `)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var errorResponseBody ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&errorResponseBody); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedCode := apperror.InvalidRequest
+	if errorResponseBody.Code != expectedCode || errorResponseBody.Status != expectedStatus {
+		t.Errorf("expected code %s and status %d but got %+v instead", expectedCode, expectedStatus, errorResponseBody)
+	}
+}
+
+func TestEndpointErrorCode(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5"], "strict": true}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var errorResponseBody ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&errorResponseBody); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedCode := apperror.BadBetSize
+	if errorResponseBody.Code != expectedCode {
+		t.Errorf("expected code %s but got %s instead", expectedCode, errorResponseBody.Code)
+	}
+}
+
+func TestLambdaErrorType(t *testing.T) {
+	payload := json.RawMessage(`{"winningNumbers": "01 02 03 04 05 05", "additionalNumber": "07"}`)
+
+	_, err := lambdaEntry(context.Background(), payload)
+
+	lambdaErr, ok := err.(messages.InvokeResponse_Error)
+	if !ok {
+		t.Fatalf("expected a lambda error but got %T instead", err)
+	}
+
+	expectedType := "DUPLICATE_NUMBER"
+	if lambdaErr.Type != expectedType {
+		t.Errorf("expected error type %s but got %s instead", expectedType, lambdaErr.Type)
+	}
+}
+
+func TestResponseJSONArrays(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": [1, 2, 3, 4, 5, 6], "additionalNumber": 7, "bets": [[1, 2, 3, 4, 5, 7], "1,2,3,4,5,R"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusOK
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Fatalf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expectedPrizes := []string{"Group 2", "Group 1 + 2 + 3"}
+	if len(response.Results) != len(expectedPrizes) {
+		t.Fatalf("expected %d results but got %+v instead", len(expectedPrizes), response.Results)
+	}
+
+	for i, expectedPrize := range expectedPrizes {
+		if response.Results[i].Prize != expectedPrize {
+			t.Errorf("expected prize: %s but got %s instead", expectedPrize, response.Results[i].Prize)
+		}
+	}
+}
+
+func TestEndpointJSONArrayBetErrors(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": [1, 2, 3, 4, 5, 6], "additionalNumber": 7, "bets": [[1,2,3,4,5,6], [0,1,2,3,4,5], [1,2,3,4,5,50], [1,2,3,4,3,5]]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %d got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response Response
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if len(response.Results) != 1 || response.Results[0].Index != 0 {
+		t.Fatalf("expected a result for bet 0 but got %+v instead", response.Results)
+	}
+
+	expectedErrors := []struct {
+		input       string
+		reason      string
+		numberIndex int
+	}{
+		{"[0,1,2,3,4,5]", "number not within range: 0 at index 0: [0 1 2 3 4 5]", 0},
+		{"[1,2,3,4,5,50]", "number not within range: 50 at index 5: [1 2 3 4 5 50]", 5},
+		{"[1,2,3,4,3,5]", "duplicate numbers found at index 4: [1 2 3 4 3 5]", 4},
+	}
+	if len(response.Errors) != len(expectedErrors) {
+		t.Fatalf("expected %d errors but got %+v instead", len(expectedErrors), response.Errors)
+	}
+
+	for i, expected := range expectedErrors {
+		e := response.Errors[i]
+		if e.Index != i+1 || e.Input != expected.input || e.Reason != expected.reason || e.Column != 0 {
+			t.Errorf("expected error %d for %s: %q but got %+v instead", i+1, expected.input, expected.reason, e)
+		}
+
+		if e.NumberIndex == nil || *e.NumberIndex != expected.numberIndex {
+			t.Errorf("expected number index %d for %s but got %v instead", expected.numberIndex, expected.input, e.NumberIndex)
+		}
+	}
+}
+
+func TestEndpointJSONArrayNumbersOutOfRange(t *testing.T) {
+	tests := []struct {
+		payload string
+		message string
+	}{
+		{
+			`{"winningNumbers": [1, 2, 3, 4, 5, 0], "additionalNumber": 7, "bets": [[1, 2, 3, 10, 11, 12]]}`,
+			"number not within range: 0 at index 5: [1 2 3 4 5 0]",
+		},
+		{
+			`{"winningNumbers": [1, 2, 3, 4, 5, 6], "additionalNumber": -7, "bets": [[1, 2, 3, 10, 11, 12]]}`,
+			"number not within range: -7",
+		},
+		{
+			`{"winningNumbers": [1, 2, 3, 4, 5, 6], "additionalNumber": 7, "bets": [[-1, 2, 3, 10, 11, 12]], "strict": true}`,
+			"number not within range: -1 at index 0: [-1 2 3 10 11 12]",
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.payload))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status: %d got %d for %s", http.StatusBadRequest, res.StatusCode, test.payload)
+		}
+
+		var body ErrorResponseBody
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("expected error to be nil got %v", err)
+		}
+
+		if body.Code != apperror.InvalidNumber || body.Message != test.message {
+			t.Errorf("expected %s %q but got %s %q", apperror.InvalidNumber, test.message, body.Code, body.Message)
+		}
+	}
+}
+
+func TestEndpointInvalidNumbersType(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": {"numbers": 1}, "additionalNumber": 7}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+}
+
+func TestResponseTicketText(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "bets": ["1 2 3 4 5 7"], "ticketText": "DRAW: 3912 MON 14/10/24\nA. 01 02 03 04 05 06\nB. 01 02 03 04 05 06 07 08 SYS 8\n2 DRAWS"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusOK
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Fatalf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	if response.Ticket == nil || response.Ticket.DrawNumber != 3912 || response.Ticket.DrawCount != 2 {
+		t.Errorf("expected ticket for draw 3912 over 2 draws but got %+v instead", response.Ticket)
+	}
+
+	if response.Ruleset != "6/49" {
+		t.Errorf("expected ruleset 6/49 but got %s instead", response.Ruleset)
+	}
+
+	expected := []struct {
+		index int
+		label string
+		prize string
+	}{
+		{0, "", "Group 2"},
+		{1, "A", "Group 1"},
+		{2, "B", "Group 1 + 2 + 3 + 4"},
+	}
+	if len(response.Results) != len(expected) {
+		t.Fatalf("expected %d results but got %+v instead", len(expected), response.Results)
+	}
+
+	for i, e := range expected {
+		r := response.Results[i]
+		if r.Index != e.index || r.Label != e.label || r.Prize != e.prize {
+			t.Errorf("expected result %+v but got index %d, label %q, prize %s instead", e, r.Index, r.Label, r.Prize)
+		}
+	}
+}
+
+func TestResponseTicketBoardOutsideHistoricalRange(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "ticketText": "DRAW: 2500 THU 7/1/10\nA. 01 02 03 04 05 46\nB. 01 02 03 04 05 06"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	var response Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	if response.Ruleset != "6/45" {
+		t.Errorf("expected ruleset 6/45 but got %s instead", response.Ruleset)
+	}
+
+	expectedError := BetError{Index: 0, Input: "A. 1 2 3 4 5 46", Reason: "invalid board A: number not within range: 46"}
+	if len(response.Errors) != 1 || response.Errors[0] != expectedError {
+		t.Errorf("expected error: %+v but got %+v instead", expectedError, response.Errors)
+	}
+
+	if len(response.Results) != 1 || response.Results[0].Label != "B" {
+		t.Errorf("expected a single result for board B but got %+v instead", response.Results)
+	}
+}
+
+func TestResponseTicketBoardTypos(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "ticketText": "A. 1 2 3 4 5 60\nB. 1 2 3 4 4 5\nC. 1 2 3 4 5 6\nD. 0 1 2 3 4 SYS R"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %d got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response Response
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	expectedErrors := []BetError{
+		{Index: 0, Input: "A. 1 2 3 4 5 60", Reason: "invalid board A: number not within range: 60"},
+		{Index: 1, Input: "B. 1 2 3 4 4 5", Reason: "invalid board B: duplicate number 4"},
+		{Index: 3, Input: "D. 0 1 2 3 4 R", Reason: "invalid board D: number not within range: 0"},
+	}
+	if len(response.Errors) != len(expectedErrors) {
+		t.Fatalf("expected errors: %+v but got %+v instead", expectedErrors, response.Errors)
+	}
+
+	for i, expectedError := range expectedErrors {
+		if response.Errors[i] != expectedError {
+			t.Errorf("expected error: %+v but got %+v instead", expectedError, response.Errors[i])
+		}
+	}
+
+	if len(response.Results) != 1 || response.Results[0].Label != "C" {
+		t.Errorf("expected a single result for board C but got %+v instead", response.Results)
+	}
+
+	serializedPayload = []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "ticketText": "A. 1 2 3 4 5 60\nB. 1 2 3 4 5 6", "strict": true}`)
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(serializedPayload))
+	w = httptest.NewRecorder()
+	handler(w, req)
+	strictRes := w.Result()
+	defer strictRes.Body.Close()
+
+	if strictRes.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status: %d got %d in strict mode", http.StatusBadRequest, strictRes.StatusCode)
+	}
+}
+
+func TestEndpointInvalidTicketText(t *testing.T) {
+	serializedPayload := []byte(`{"winningNumbers": "01 02 03 04 05 06", "additionalNumber": "07", "ticketText": "DRAW: 3912"}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusBadRequest
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+
+	var body ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if body.Code != apperror.InvalidTicket {
+		t.Errorf("expected code %s got %s", apperror.InvalidTicket, body.Code)
+	}
+}
+
+func useDrawStore(t *testing.T, draws ...drawstore.Draw) {
+	t.Helper()
+
+	m, err := drawstore.NewMemory(draws...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	previous := drawStore
+	drawStore = m
+	t.Cleanup(func() { drawStore = previous })
+}
+
+// useToday makes the given date today for deciding which draws are yet to be
+// held.
+func useToday(t *testing.T, date string) {
+	t.Helper()
+
+	today, err := time.Parse(drawstore.DateLayout, date)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	previous := now
+	now = func() time.Time { return today }
+	t.Cleanup(func() { now = previous })
+}
+
+func TestResponseStoredDraw(t *testing.T) {
+	useDrawStore(t, drawstore.Draw{
+		DrawNumber: 3912,
+		DrawDate:   "2024-10-14",
+		TotoDraw: totodraw.TotoDraw{
+			WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 4, 5, 6},
+			AdditionalNumber: 7,
+			GroupPrizes:      &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000},
+		},
+	})
+
+	payloads := []string{
+		`{"drawNumber": 3912, "bets": ["1 2 3 4 5 7"]}`,
+		`{"drawDate": "2024-10-14", "bets": ["1 2 3 4 5 7"]}`,
+		`{"ticketText": "DRAW: 3912 MON 14/10/24\nA. 1 2 3 4 5 7"}`,
+	}
+
+	for _, payload := range payloads {
+		response, err := lambdaHandler(context.Background(), decodeRequest(t, payload))
+		if err != nil {
+			t.Errorf("expected error to be nil for %s got %v", payload, err)
+			continue
+		}
+
+		if response.DrawNumber != 3912 || response.DrawDate != "2024-10-14" {
+			t.Errorf("expected draw 3912 on 2024-10-14 for %s but got %d on %s", payload, response.DrawNumber, response.DrawDate)
+		}
+
+		if len(response.Results) != 1 || response.Results[0].Prize != "Group 2" {
+			t.Errorf("expected a Group 2 result for %s but got %+v", payload, response.Results)
+		}
+
+		if response.TotalPayoutCents == nil || *response.TotalPayoutCents != 5000000 {
+			t.Errorf("expected a total payout of 5000000 for %s but got %v", payload, response.TotalPayoutCents)
+		}
+	}
+}
+
+func TestEndpointStoredDrawNotFound(t *testing.T) {
+	useDrawStore(t)
+
+	serializedPayload := []byte(`{"drawNumber": 3912, "bets": ["1 2 3 4 5 7"]}`)
+	reader := bytes.NewReader(serializedPayload)
+
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	expectedStatus := http.StatusNotFound
+	actualStatus := res.StatusCode
+	if actualStatus != expectedStatus {
+		t.Errorf("expected status: %d got %d", expectedStatus, actualStatus)
+	}
+}
+
+func decodeRequest(t *testing.T, payload string) Request {
+	t.Helper()
+
+	var request Request
+	if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		t.Fatalf("unexpected error decoding %s: %s", payload, err.Error())
+	}
+	return request
+}
+
+func TestResponseMultiDraw(t *testing.T) {
+	useDrawStore(t,
+		drawstore.Draw{
+			DrawNumber: 3912,
+			DrawDate:   "2024-10-14",
+			TotoDraw: totodraw.TotoDraw{
+				WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 4, 5, 6},
+				AdditionalNumber: 7,
+				GroupPrizes:      &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000},
+			},
+		},
+		drawstore.Draw{
+			DrawNumber: 3913,
+			DrawDate:   "2024-10-17",
+			TotoDraw: totodraw.TotoDraw{
+				WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 10, 11, 12},
+				AdditionalNumber: 13,
+				GroupPrizes:      &prizetable.GroupPrizes{Group1: 100000000, Group2: 5000000, Group3: 150000, Group4: 40000},
+			},
+		},
+	)
+	useToday(t, "2024-10-18")
+
+	payloads := []string{
+		`{"drawNumber": 3912, "drawCount": 3, "bets": ["1 2 3 4 5 7"]}`,
+		`{"drawDate": "2024-10-14", "drawCount": 3, "bets": ["1 2 3 4 5 7"]}`,
+		`{"ticketText": "DRAW: 3912 MON 14/10/24\nA. 1 2 3 4 5 7\n3 DRAWS"}`,
+	}
+
+	for _, payload := range payloads {
+		response, err := lambdaHandler(context.Background(), decodeRequest(t, payload))
+		if err != nil {
+			t.Errorf("expected error to be nil for %s got %v", payload, err)
+			continue
+		}
+
+		if len(response.Draws) != 3 {
+			t.Fatalf("expected 3 draws for %s but got %+v", payload, response.Draws)
+		}
+
+		expected := []struct {
+			drawNumber int
+			pending    bool
+			prize      string
+		}{
+			{3912, false, "Group 2"},
+			{3913, false, "Group 7"},
+			{3914, true, ""},
+		}
+
+		for i, e := range expected {
+			d := response.Draws[i]
+			if d.DrawNumber != e.drawNumber || d.Pending != e.pending {
+				t.Errorf("expected draw %d, pending %t for %s but got %d, %t", e.drawNumber, e.pending, payload, d.DrawNumber, d.Pending)
+			}
+
+			if e.prize != "" && (len(d.Results) != 1 || d.Results[0].Prize != e.prize) {
+				t.Errorf("expected prize %s in draw %d for %s but got %+v", e.prize, e.drawNumber, payload, d.Results)
+			}
+		}
+
+		if response.TotalPayoutCents == nil || *response.TotalPayoutCents != 5001000 {
+			t.Errorf("expected a total payout of 5001000 for %s but got %v", payload, response.TotalPayoutCents)
+		}
+	}
+}
+
+func TestResponseMultiDrawWithoutGroupPrizes(t *testing.T) {
+	useBacktestDraws(t)
+
+	response, err := lambdaHandler(context.Background(), decodeRequest(t, `{"drawNumber": 3912, "drawCount": 2, "bets": ["1 2 3 4 11 12", "1 2 3 20 21 22"]}`))
+	if err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if len(response.Draws) != 2 || response.Draws[1].Results[0].Prize != "Group 1" {
+		t.Fatalf("expected a Group 1 win in draw 3913 but got %+v", response.Draws)
+	}
+
+	if response.TotalPayoutCents != nil {
+		t.Errorf("expected no total payout but got %d", *response.TotalPayoutCents)
+	}
+
+	if response.DrawsWithoutGroupPrizes != 1 {
+		t.Errorf("expected 1 draw without group prizes but got %d", response.DrawsWithoutGroupPrizes)
+	}
+}
+
+func TestEndpointMultiDrawErrors(t *testing.T) {
+	useDrawStore(t)
+
+	payloads := []string{
+		`{"drawCount": 2, "bets": ["1 2 3 4 5 6"]}`,
+		`{"drawNumber": 3912, "drawCount": 2, "winningNumbers": "1 2 3 4 5 6", "additionalNumber": "7"}`,
+		`{"drawNumber": 3912, "drawCount": 1000, "bets": ["1 2 3 4 5 6"]}`,
+		`{"drawNumber": 3912, "drawCount": -3, "bets": ["1 2 3 4 5 6"]}`,
+		`{"drawNumber": 3912, "drawCount": 2, "bets": ["1 2 3 4 5 6"], "groupPrizes": {"group1": 100000000}}`,
+	}
+
+	for _, payload := range payloads {
+		_, err := lambdaHandler(context.Background(), decodeRequest(t, payload))
+		if apperror.CodeOf(err) != apperror.InvalidRequest {
+			t.Errorf("expected %s for %s but got %v", apperror.InvalidRequest, payload, err)
+		}
+	}
+}
+
+func TestResponseMultiDrawMissingPastDraw(t *testing.T) {
+	useDrawStore(t,
+		drawstore.Draw{
+			DrawNumber: 3912,
+			DrawDate:   "2024-10-14",
+			TotoDraw:   totodraw.TotoDraw{WinningNumbers: totodraw.WinningNumbers{1, 2, 3, 4, 5, 6}, AdditionalNumber: 7},
+		},
+		drawstore.Draw{
+			DrawNumber: 3914,
+			DrawDate:   "2024-10-21",
+			TotoDraw:   totodraw.TotoDraw{WinningNumbers: totodraw.WinningNumbers{1, 2, 3, 4, 5, 6}, AdditionalNumber: 7},
+		},
+	)
+	useToday(t, "2024-10-22")
+
+	tests := []struct {
+		payload string
+		pending []bool
+	}{
+		// Draw 3913 is missing between two stored draws.
+		{`{"drawNumber": 3912, "drawCount": 2, "bets": ["1 2 3 4 5 7"]}`, nil},
+		// Draw 3915 falls on Thursday 24 October, draw 3916 on Monday 28
+		// October, both after today.
+		{`{"drawNumber": 3914, "drawCount": 3, "bets": ["1 2 3 4 5 7"]}`, []bool{false, true, true}},
+	}
+
+	for _, test := range tests {
+		response, err := lambdaHandler(context.Background(), decodeRequest(t, test.payload))
+		if test.pending == nil {
+			if apperror.CodeOf(err) != apperror.DrawNotFound {
+				t.Errorf("expected %s for %s but got %v", apperror.DrawNotFound, test.payload, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("expected error to be nil for %s got %v", test.payload, err)
+		}
+
+		for i, pending := range test.pending {
+			if response.Draws[i].Pending != pending {
+				t.Errorf("expected draw %d pending %t but got %t", response.Draws[i].DrawNumber, pending, response.Draws[i].Pending)
+			}
+		}
+	}
+
+	useToday(t, "2024-10-25")
+	_, err := lambdaHandler(context.Background(), decodeRequest(t, `{"drawNumber": 3914, "drawCount": 3, "bets": ["1 2 3 4 5 7"]}`))
+	if apperror.CodeOf(err) != apperror.DrawNotFound {
+		t.Errorf("expected draw 3915, held on 24 October, to be not found but got %v", err)
+	}
+}
+
+func TestResponseCost(t *testing.T) {
+	request := decodeRequest(t, `{"winningNumbers": "1 2 3 4 5 6", "additionalNumber": "7", "bets": ["1 2 3 4 5 6", "1 2 3 4 5 6 7 8", "1 2 3 4 5 R", "1 2 3"]}`)
+
+	response, err := lambdaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	expectedCosts := []int{100, 2800, 4400}
+	for i, expectedCost := range expectedCosts {
+		if response.Results[i].CostCents != expectedCost {
+			t.Errorf("expected cost %d but got %d instead", expectedCost, response.Results[i].CostCents)
+		}
+	}
+
+	if response.CostCents != 7300 {
+		t.Errorf("expected total cost 7300 but got %d instead", response.CostCents)
+	}
+
+	previous := unitPriceCents
+	unitPriceCents = 50
+	t.Cleanup(func() { unitPriceCents = previous })
+
+	response, err = lambdaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if response.CostCents != 3650 {
+		t.Errorf("expected total cost 3650 but got %d instead", response.CostCents)
+	}
+}
+
+func TestResponseMultiDrawCost(t *testing.T) {
+	useDrawStore(t, drawstore.Draw{
+		DrawNumber: 3912,
+		DrawDate:   "2024-10-14",
+		TotoDraw: totodraw.TotoDraw{
+			WinningNumbers:   totodraw.WinningNumbers{1, 2, 3, 4, 5, 6},
+			AdditionalNumber: 7,
+		},
+	})
+	useToday(t, "2024-10-15")
+
+	response, err := lambdaHandler(context.Background(), decodeRequest(t, `{"ticketText": "DRAW: 3912 MON 14/10/24\nA. 1 2 3 4 5 7\nB. 1 2 3 4 5 6 7 SYS 7\n3 DRAWS"}`))
+	if err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	for _, d := range response.Draws {
+		if d.CostCents != 800 {
+			t.Errorf("expected draw %d to cost 800 but got %d instead", d.DrawNumber, d.CostCents)
+		}
+	}
+
+	if response.CostCents != 2400 {
+		t.Errorf("expected total cost 2400 but got %d instead", response.CostCents)
+	}
+}
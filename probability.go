@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/probability"
+	"github.com/aikchun/totoprizecheck/internal/ruleset"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// ProbabilityRequest asks for the odds of a bet type, or of every bet type
+// when BetType is empty, under the rules in force on DrawDate.
+type ProbabilityRequest struct {
+	BetType        string `json:"betType,omitempty"`
+	DrawDate       string `json:"drawDate,omitempty"`
+	PrizeStructure string `json:"prizeStructure,omitempty"`
+}
+
+type ProbabilityResponse struct {
+	Ruleset        string             `json:"ruleset"`
+	PrizeStructure string             `json:"prizeStructure"`
+	Odds           []probability.Odds `json:"odds"`
+}
+
+func odds(request ProbabilityRequest) (ProbabilityResponse, error) {
+	var response ProbabilityResponse
+
+	rules, table, structure, err := gameRules(request.DrawDate, request.PrizeStructure)
+	if err != nil {
+		return response, err
+	}
+
+	betTypes := []string{request.BetType}
+	if request.BetType == "" {
+		betTypes = ruleBetTypes(rules)
+	}
+
+	for _, betType := range betTypes {
+		o, err := probability.ForBetType(table, betType, rules.MaxNumber)
+		if err != nil {
+			return response, err
+		}
+		response.Odds = append(response.Odds, o)
+	}
+
+	response.Ruleset = rules.Name
+	response.PrizeStructure = structure
+	return response, nil
+}
+
+// gameRules returns the ruleset in force on a draw date, or the latest
+// ruleset, with its prize table or the named prize structure.
+func gameRules(drawDate string, prizeStructure string) (ruleset.Ruleset, *prizetable.Table, string, error) {
+	rules, err := rulesetForRequest(Request{DrawDate: drawDate})
+	if err != nil {
+		return rules, nil, "", err
+	}
+
+	if prizeStructure == "" {
+		prizeStructure = rules.PrizeStructure
+	}
+
+	table, ok := prizetable.Structure(prizeStructure)
+	if !ok {
+		return rules, nil, "", apperror.Errorf(apperror.UnknownPrizeStructure, "unknown prize structure: %s", prizeStructure)
+	}
+	return rules, table, prizeStructure, nil
+}
+
+// ruleBetTypes returns the bet types allowed by a ruleset, System Roll last.
+func ruleBetTypes(rules ruleset.Ruleset) []string {
+	var betTypes []string
+	for _, size := range rules.BetSizes {
+		bet := make(totodraw.Bet, size)
+		for i := range bet {
+			bet[i] = i + 1
+		}
+		betTypes = append(betTypes, bet.GetBetType())
+	}
+	return append(betTypes, "System Roll")
+}
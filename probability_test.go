@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+)
+
+func TestOddsEveryBetType(t *testing.T) {
+	response, err := odds(ProbabilityRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedBetTypes := []string{"Ordinary", "System 7", "System 8", "System 9", "System 10", "System 11", "System 12", "System Roll"}
+	if len(response.Odds) != len(expectedBetTypes) {
+		t.Fatalf("expected %d bet types but got %d", len(expectedBetTypes), len(response.Odds))
+	}
+
+	for i, betType := range expectedBetTypes {
+		if response.Odds[i].BetType != betType {
+			t.Errorf("expected bet type %s but got %s instead", betType, response.Odds[i].BetType)
+		}
+	}
+
+	if response.Ruleset != "6/49" || response.PrizeStructure != "current" {
+		t.Errorf("expected the 6/49 ruleset and current prize structure but got %s and %s", response.Ruleset, response.PrizeStructure)
+	}
+}
+
+func TestOddsHistoricalRuleset(t *testing.T) {
+	response, err := odds(ProbabilityRequest{BetType: "Ordinary", DrawDate: "2010-01-07"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Ruleset != "6/45" || len(response.Odds) != 1 {
+		t.Fatalf("expected the Ordinary odds of the 6/45 ruleset but got %+v", response)
+	}
+
+	expectedFraction := "1/8145060"
+	actualFraction := response.Odds[0].Outcomes[len(response.Odds[0].Outcomes)-1].Probability.Fraction
+	if actualFraction != expectedFraction {
+		t.Errorf("expected Group 1 odds of %s but got %s instead", expectedFraction, actualFraction)
+	}
+}
+
+func TestEndpointProbability(t *testing.T) {
+	reader := bytes.NewReader([]byte(`{"betType": "System 13"}`))
+	req := httptest.NewRequest(http.MethodPost, "/probability", reader)
+	w := httptest.NewRecorder()
+	jsonHandler(odds)(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status: %d got %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	var body ErrorResponseBody
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if body.Code != apperror.BadBetSize {
+		t.Errorf("expected code %s got %s", apperror.BadBetSize, body.Code)
+	}
+}
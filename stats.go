@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/aikchun/totoprizecheck/internal/ruleset"
+	"github.com/aikchun/totoprizecheck/internal/stats"
+)
+
+// defaultTopCombinations is how many of the most common pairs and triples
+// are reported by default.
+const defaultTopCombinations = 10
+
+// StatsRequest selects the stored draws to compute statistics over, the same
+// way as a BacktestRequest.
+type StatsRequest struct {
+	FromDrawNumber int    `json:"fromDrawNumber,omitempty"`
+	ToDrawNumber   int    `json:"toDrawNumber,omitempty"`
+	FromDate       string `json:"fromDate,omitempty"`
+	ToDate         string `json:"toDate,omitempty"`
+	Top            int    `json:"top,omitempty"`
+}
+
+// numberStats computes the statistics of the stored draws in range. Numbers
+// run up to the highest number of the rulesets of those draws.
+func numberStats(request StatsRequest) (stats.Stats, error) {
+	draws, err := drawsInRange(request.FromDrawNumber, request.ToDrawNumber, request.FromDate, request.ToDate)
+	if err != nil {
+		return stats.Stats{}, err
+	}
+
+	maxNumber := ruleset.Default.Latest().MaxNumber
+	if len(draws) > 0 {
+		maxNumber = 0
+	}
+
+	for _, d := range draws {
+		rules, err := rulesForDraw(d)
+		if err != nil {
+			return stats.Stats{}, err
+		}
+
+		if rules.MaxNumber > maxNumber {
+			maxNumber = rules.MaxNumber
+		}
+	}
+
+	top := request.Top
+	if top <= 0 {
+		top = defaultTopCombinations
+	}
+
+	return stats.Compute(draws, maxNumber, top), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/stats"
+)
+
+func TestNumberStats(t *testing.T) {
+	useBacktestDraws(t)
+
+	s, err := numberStats(StatsRequest{FromDrawNumber: 3912})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Draws != 2 || len(s.Numbers) != 49 {
+		t.Errorf("expected 2 draws over 49 numbers but got %+v", s)
+	}
+
+	if s.Numbers[0].Winning != 2 || s.Numbers[6].Additional != 1 || s.Numbers[6].DrawsSince != 1 {
+		t.Errorf("unexpected number stats %+v", s.Numbers[:7])
+	}
+
+	if len(s.Pairs) != defaultTopCombinations {
+		t.Errorf("expected %d pairs but got %d", defaultTopCombinations, len(s.Pairs))
+	}
+}
+
+func TestNumberStatsHistoricalRange(t *testing.T) {
+	useBacktestDraws(t)
+
+	s, err := numberStats(StatsRequest{ToDate: "2014-10-06"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Draws != 1 || len(s.Numbers) != 45 {
+		t.Errorf("expected 1 draw over 45 numbers but got %+v", s)
+	}
+}
+
+func TestCLIStats(t *testing.T) {
+	useBacktestDraws(t)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := runCLI([]string{"stats"}, strings.NewReader(`{"top": 1}`), &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 but got %d instead: %s", exitCode, stderr.String())
+	}
+
+	var s stats.Stats
+	if err := json.NewDecoder(&stdout).Decode(&s); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if s.Draws != 3 || len(s.Triples) != 1 {
+		t.Errorf("expected 3 draws and 1 triple but got %+v", s)
+	}
+}
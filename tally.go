@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizepool"
+	"github.com/aikchun/totoprizecheck/internal/prizetable"
+	"github.com/aikchun/totoprizecheck/internal/totodraw"
+)
+
+// maxTallyErrors is how many invalid bets a tally reports. Any more are only
+// counted.
+const maxTallyErrors = 100
+
+// TallyResponse counts the winners among a set of bets. Winners counts the
+// winning units of each group, system bets counting once for every winning
+// ordinary combination, ready to divide a prize pool with. FixedCents holds
+// the fixed prizes won, and PayoutCents the prizes won in all when the draw
+// has group prize amounts.
+type TallyResponse struct {
+	DrawNumber   int               `json:"drawNumber,omitempty"`
+	DrawDate     string            `json:"drawDate,omitempty"`
+	Ruleset      string            `json:"ruleset"`
+	TotoDraw     totodraw.TotoDraw `json:"totoDraw"`
+	Bets         int               `json:"bets"`
+	Combinations int               `json:"combinations"`
+	WinningBets  int               `json:"winningBets"`
+	Winners      prizepool.Winners `json:"winners"`
+	FixedCents   int               `json:"fixedCents"`
+	PayoutCents  *int              `json:"payoutCents,omitempty"`
+	InvalidBets  int               `json:"invalidBets"`
+	Errors       []BetError        `json:"errors,omitempty"`
+}
+
+// tally checks the bets read from r, one per line, against the draw of the
+// request and counts the winners. Bets are read as they are checked, so any
+// number of them can be tallied in constant memory. Blank lines and lines
+// starting with # are skipped, and the bets of the request are ignored.
+func tally(request Request, r io.Reader) (TallyResponse, error) {
+	var response TallyResponse
+
+	draw, rules, request, err := drawForRequest(request)
+	if err != nil {
+		return response, err
+	}
+
+	opts := checkOptions{MaxNumber: rules.MaxNumber}
+
+	var prizes prizetable.Prize
+	payout := 0
+	masked := newMaskedDraw(draw)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		index := response.Bets + response.InvalidBets
+		bet, err := parseBet(line, rules)
+		if err != nil {
+			response.InvalidBets++
+			if len(response.Errors) < maxTallyErrors {
+				response.Errors = append(response.Errors, newBetError(index, line, err))
+			}
+			continue
+		}
+
+		result := matchTotoDrawWithBet(masked, bet, opts)
+
+		response.Bets++
+		response.Combinations += bet.Combinations(opts.maxNumber())
+		prizes = prizes.Add(result.PrizeDetail)
+
+		if result.PrizeDetail.Won {
+			response.WinningBets++
+		}
+
+		if result.PayoutCents != nil {
+			payout += *result.PayoutCents
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return response, apperror.Errorf(apperror.InvalidRequest, "unable to read bets: %s", err.Error())
+	}
+
+	response.DrawNumber = request.DrawNumber
+	response.DrawDate = request.DrawDate
+	response.Ruleset = rules.Name
+	response.TotoDraw = draw
+	response.Winners = prizepool.Winners{
+		Group1: prizes.Group1,
+		Group2: prizes.Group2,
+		Group3: prizes.Group3,
+		Group4: prizes.Group4,
+		Group5: prizes.Group5,
+		Group6: prizes.Group6,
+		Group7: prizes.Group7,
+	}
+	response.FixedCents = prizes.FixedCents
+	if draw.GroupPrizes != nil {
+		response.PayoutCents = &payout
+	}
+	return response, nil
+}
+
+// tallyRequest reads the draw of a tally from query parameters, or command
+// line flags, with the names of the Request fields.
+func tallyRequest(get func(name string) string) (Request, error) {
+	request := Request{
+		WinningNumbers:   NumbersInput(get("winningNumbers")),
+		AdditionalNumber: NumbersInput(get("additionalNumber")),
+		DrawDate:         get("drawDate"),
+		PrizeStructure:   get("prizeStructure"),
+	}
+
+	if n := get("drawNumber"); n != "" {
+		drawNumber, err := strconv.Atoi(n)
+		if err != nil {
+			return request, apperror.Errorf(apperror.InvalidRequest, "invalid draw number %s", n)
+		}
+		request.DrawNumber = drawNumber
+	}
+
+	return request, nil
+}
+
+// tallyHandler tallies the bets in the request body, one per line, against
+// the draw given by the query parameters.
+func tallyHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		writeErrorHttp(w, apperror.New(apperror.MethodNotAllowed, "method not allowed"))
+		return
+	}
+
+	request, err := tallyRequest(r.URL.Query().Get)
+	if err != nil {
+		writeErrorHttp(w, err)
+		return
+	}
+
+	res, err := tally(request, r.Body)
+	if err != nil {
+		writeErrorHttp(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aikchun/totoprizecheck/internal/apperror"
+	"github.com/aikchun/totoprizecheck/internal/prizepool"
+)
+
+const tallyBets = `# syndicate
+1 2 3 4 5 6
+1 2 3 4 5 7
+
+1 2 3 4 5 6 7 8
+1 2 3 4 5 R
+20 21 22 23 24 25
+1 2 3 4 a5 6
+`
+
+func TestTally(t *testing.T) {
+	request := Request{WinningNumbers: "1 2 3 4 5 6", AdditionalNumber: "7"}
+
+	response, err := tally(request, strings.NewReader(tallyBets))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Bets != 5 || response.InvalidBets != 1 || response.WinningBets != 4 {
+		t.Errorf("unexpected counts %+v", response)
+	}
+
+	if response.Combinations != 1+1+28+44+1 {
+		t.Errorf("expected 75 combinations but got %d", response.Combinations)
+	}
+
+	// Every winning unit is counted: the System 8 bet wins Group 1, 2, 3
+	// and 4 shares and the System Roll bet wins Group 1, 2 and 3 shares on
+	// top of its fixed prizes.
+	var expected prizepool.Winners
+	for _, bet := range []string{"1 2 3 4 5 6", "1 2 3 4 5 7", "1 2 3 4 5 6 7 8", "1 2 3 4 5 R"} {
+		check, err := lambdaHandler(context.Background(), Request{WinningNumbers: "1 2 3 4 5 6", AdditionalNumber: "7", Bets: []NumbersInput{NumbersInput(bet)}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		p := check.Results[0].PrizeDetail
+		expected.Group1 += p.Group1
+		expected.Group2 += p.Group2
+		expected.Group3 += p.Group3
+		expected.Group4 += p.Group4
+		expected.Group5 += p.Group5
+		expected.Group6 += p.Group6
+		expected.Group7 += p.Group7
+	}
+
+	if response.Winners != expected {
+		t.Errorf("expected winners %+v but got %+v", expected, response.Winners)
+	}
+
+	if len(response.Errors) != 1 || response.Errors[0].Index != 5 || response.Errors[0].Column != 9 {
+		t.Errorf("expected an error for bet 5 at column 9 but got %+v", response.Errors)
+	}
+}
+
+// manyBets streams n ordinary bets without holding them in memory.
+func manyBets(n int) io.Reader {
+	r, w := io.Pipe()
+	go func() {
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, "%d %d %d %d %d %d\n", 1+i%44, 2+i%44, 3+i%44, 4+i%44, 5+i%44, 6+i%44)
+		}
+		w.Close()
+	}()
+	return r
+}
+
+func TestTallyStream(t *testing.T) {
+	request := Request{WinningNumbers: "1 2 3 4 5 6", AdditionalNumber: "7"}
+
+	response, err := tally(request, manyBets(44000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Out of every 44 bets, 1 2 3 4 5 6 wins Group 1, 2 3 4 5 6 7 Group 2,
+	// 3 4 5 6 7 8 Group 4 and 4 5 6 7 8 9 Group 6.
+	expected := prizepool.Winners{Group1: 1000, Group2: 1000, Group4: 1000, Group6: 1000}
+	if response.Bets != 44000 || response.Winners != expected {
+		t.Errorf("expected winners %+v but got %+v", expected, response)
+	}
+}
+
+func TestTallyErrorsAreBounded(t *testing.T) {
+	request := Request{WinningNumbers: "1 2 3 4 5 6", AdditionalNumber: "7"}
+
+	response, err := tally(request, strings.NewReader(strings.Repeat("1 2 3\n", maxTallyErrors+10)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.InvalidBets != maxTallyErrors+10 || len(response.Errors) != maxTallyErrors {
+		t.Errorf("expected %d invalid bets and %d errors but got %d and %d", maxTallyErrors+10, maxTallyErrors, response.InvalidBets, len(response.Errors))
+	}
+}
+
+func TestEndpointTally(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/tally?winningNumbers=1+2+3+4+5+6&additionalNumber=7", strings.NewReader(tallyBets))
+	w := httptest.NewRecorder()
+	tallyHandler(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %d got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response TallyResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if response.Bets != 5 || response.Winners.Group1 != 3 {
+		t.Errorf("unexpected tally %+v", response)
+	}
+}
+
+func TestCLITally(t *testing.T) {
+	useBacktestDraws(t)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := runCLI([]string{"tally", "-drawNumber", "3912"}, strings.NewReader(tallyBets), &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 but got %d instead: %s", exitCode, stderr.String())
+	}
+
+	var response TallyResponse
+	if err := json.NewDecoder(&stdout).Decode(&response); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if response.DrawNumber != 3912 || response.PayoutCents == nil {
+		t.Errorf("expected a tally of draw 3912 with payouts but got %+v", response)
+	}
+
+	exitCode = runCLI([]string{"tally", "-drawNumber", "x"}, strings.NewReader(""), &stdout, &stderr)
+	if exitCode != apperror.ExitCode(apperror.New(apperror.InvalidRequest, "")) {
+		t.Errorf("expected the exit code of an invalid request but got %d", exitCode)
+	}
+}